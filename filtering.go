@@ -0,0 +1,114 @@
+package tabulate
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Condition keeps the rows whose value in the named column compares to
+// Value as Op says. Op is one of =, !=, <, <=, >, >=, ~ (contains) and !~
+// (does not contain). Numbers are compared numerically, and everything else
+// by its text, naturally. Nil and missing values only ever satisfy != and
+// !~.
+type Condition struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+// Longer operators go first, so <= is not taken for <.
+var conditionOps = []string{"!=", "<=", ">=", "!~", "==", "=", "<", ">", "~"}
+
+// ParseCondition reads a Condition out of text such as "Size>=100" or
+// "Name~file", for instance from a command-line flag.
+func ParseCondition(text string) (Condition, error) {
+	opAt := strings.IndexAny(text, "!=<>~")
+	if opAt < 1 {
+		return Condition{}, fmt.Errorf(
+			"Condition %q must look like name, operator, value.", text,
+		)
+	}
+
+	for _, op := range conditionOps {
+		if strings.HasPrefix(text[opAt:], op) {
+			if op == "==" {
+				op = "="
+			}
+			return Condition{
+				strings.TrimSpace(text[:opAt]),
+				op,
+				strings.TrimSpace(text[opAt+len(op):]),
+			}, nil
+		}
+	}
+	return Condition{}, fmt.Errorf("Condition %q has no known operator.", text)
+}
+
+// matches reports whether value satisfies the condition.
+func (c Condition) matches(value reflect.Value) (bool, error) {
+	value = underlying(value)
+	if !value.IsValid() {
+		return c.Op == "!=" || c.Op == "!~", nil
+	}
+
+	if c.Op == "~" || c.Op == "!~" {
+		contains := strings.Contains(valueText(value), c.Value)
+		return contains == (c.Op == "~"), nil
+	}
+
+	var cmp int
+	number, _, err := big.ParseFloat(c.Value, 10, 64, big.ToNearestEven)
+	if valueRank(value) == 0 && err == nil {
+		cmp = toBig(value).Cmp(number)
+	} else {
+		cmp = compareNatural(valueText(value), c.Value)
+	}
+
+	switch c.Op {
+	case "=":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("Condition has unknown operator %q.", c.Op)
+}
+
+// filterRows returns the rows of table kept by filter (if not nil) and
+// satisfying every condition, along with their positions in table.
+func filterRows(table reflect.Value, filter func(row interface{}) bool,
+	conditions []Condition, getters []cellGetter) (reflect.Value, []int, error) {
+
+	kept := reflect.MakeSlice(reflect.SliceOf(table.Type().Elem()), 0, 0)
+	// Not nil even if no row is kept, as nil positions are rows left as
+	// they are
+	positions := []int{}
+
+rows:
+	for i := 0; i < table.Len(); i++ {
+		if filter != nil && !filter(table.Index(i).Interface()) {
+			continue
+		}
+		for k, condition := range conditions {
+			match, err := condition.matches(getters[k](table, i))
+			if err != nil {
+				return reflect.Value{}, nil, err
+			}
+			if !match {
+				continue rows
+			}
+		}
+		kept = reflect.Append(kept, table.Index(i))
+		positions = append(positions, i)
+	}
+	return kept, positions, nil
+}
@@ -0,0 +1,178 @@
+package tabulate
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// Aggregate works out a footer cell from the non-nil values of a column,
+// such as Sum or Max.
+type Aggregate func(values []interface{}) interface{}
+
+// Footer is a row drawn below the body of the table, after the format's
+// AboveFooter line, such as a line of totals. Its cells are keyed by
+// column name (as used by Layout.Columns), and columns with neither Text
+// nor an Aggregate are left empty.
+type Footer struct {
+	// Text holds cells shown as is, such as a "Total" label.
+	Text map[string]string
+	// Aggregates holds cells worked out from the values of their column,
+	// and written out with that column's format.
+	Aggregates map[string]Aggregate
+}
+
+// Sum adds up numbers, skipping values that are not numbers. Ints and uints
+// add up exactly, to an int64, or a uint64 if the sum is too big for one,
+// or else a *big.Int. Once there are floats, the sum is a float64, or a
+// float32 if every number is a float32.
+func Sum(values []interface{}) interface{} {
+	intSum := new(big.Int)
+	var floatSum float64
+	floats, float32s := false, true
+
+	for _, value := range values {
+		number := reflect.ValueOf(value)
+		switch valueKind(number) {
+		case reflect.Int:
+			intSum.Add(intSum, big.NewInt(number.Int()))
+			float32s = false
+		case reflect.Uint:
+			intSum.Add(intSum, new(big.Int).SetUint64(number.Uint()))
+			float32s = false
+		case reflect.Float64:
+			floatSum += number.Float()
+			floats = true
+			float32s = float32s && number.Kind() == reflect.Float32
+		}
+	}
+
+	switch {
+	case floats && float32s:
+		return float32(floatSum)
+	case floats:
+		sum, _ := new(big.Float).SetInt(intSum).Float64()
+		return floatSum + sum
+	case intSum.IsInt64():
+		return intSum.Int64()
+	case intSum.IsUint64():
+		return intSum.Uint64()
+	}
+	return intSum
+}
+
+// Mean averages numbers as a float64, or a float32 if every number is a
+// float32, skipping values that are not numbers. It is nil if there are no
+// numbers.
+func Mean(values []interface{}) interface{} {
+	sum := 0.0
+	count := 0
+	float32s := true
+
+	for _, value := range values {
+		number := reflect.ValueOf(value)
+		switch valueKind(number) {
+		case reflect.Int:
+			sum += float64(number.Int())
+		case reflect.Uint:
+			sum += float64(number.Uint())
+		case reflect.Float64:
+			sum += number.Float()
+		default:
+			continue
+		}
+		float32s = float32s && number.Kind() == reflect.Float32
+		count++
+	}
+
+	if count == 0 {
+		return nil
+	}
+	if float32s {
+		return float32(sum / float64(count))
+	}
+	return sum / float64(count)
+}
+
+// Min is the smallest value, compared the way SortBy compares them.
+func Min(values []interface{}) interface{} {
+	return pick(values, -1)
+}
+
+// Max is the largest value, compared the way SortBy compares them.
+func Max(values []interface{}) interface{} {
+	return pick(values, 1)
+}
+
+// Count is the number of (non-nil) values.
+func Count(values []interface{}) interface{} {
+	return len(values)
+}
+
+func pick(values []interface{}, want int) interface{} {
+	var picked interface{}
+
+	for _, value := range values {
+		if picked == nil || compareValues(
+			reflect.ValueOf(value), reflect.ValueOf(picked),
+		) == want {
+			picked = value
+		}
+	}
+	return picked
+}
+
+// valueKind sorts numbers into reflect.Int, reflect.Uint and
+// reflect.Float64, whatever their size, and everything else into
+// reflect.Invalid.
+func valueKind(value reflect.Value) reflect.Kind {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	}
+	return reflect.Invalid
+}
+
+// footerCells works out the cells of every footer for a column.
+func footerCells(footers []Footer, name string, cells []reflect.Value,
+	format *ColumnFormat, missing string) ([]string, error) {
+
+	var texts []string
+	var values []interface{}
+	gathered := false
+
+	for _, footer := range footers {
+		if text, found := footer.Text[name]; found {
+			texts = append(texts, text)
+			continue
+		}
+		aggregate, found := footer.Aggregates[name]
+		if !found || aggregate == nil {
+			texts = append(texts, "")
+			continue
+		}
+
+		if !gathered {
+			for _, cell := range cells {
+				if cell = underlying(cell); cell.IsValid() {
+					if value := exportValue(cell); value != nil {
+						values = append(values, value)
+					}
+				}
+			}
+			gathered = true
+		}
+
+		text, err := castValue(aggregate(values), format, missing)
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
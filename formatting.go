@@ -30,11 +30,36 @@ type TableFormatterInterface interface {
 	// This string appears in the table, between every "normal" row. Should
 	// not contain a return line.
 	BetweenRow(index int) string
+	// This string appears in the table, between the last "normal" row and
+	// the footer rows, when there are any. Should not contain a return
+	// line.
+	AboveFooter() string
 	// This string appears at the bottom of the table. Should not contain a
 	// return line.
 	BelowTable() string
 }
 
+// GroupFormatterInterface is implemented by formats with a line of their
+// own between groups of rows, when grouping by a column.
+type GroupFormatterInterface interface {
+	TableFormatterInterface
+	// This string appears in the table, in place of BetweenRow, between
+	// groups of rows. It is drawn even if empty, as a blank line. Should
+	// not contain a return line.
+	BetweenGroups() string
+}
+
+// SpanFormatterInterface is implemented by formats that draw lines across
+// the table, to join them up around cells spanning several columns.
+type SpanFormatterInterface interface {
+	TableFormatterInterface
+	// Junctions is passed, before every line drawn across the table,
+	// whether each gap between two columns is split by a line in the row
+	// above the line, and in the row below it. The lines at the top and
+	// the bottom of the table get the same splits above and below.
+	Junctions(above []bool, below []bool)
+}
+
 type spacerFormatting string
 
 func (s spacerFormatting) Spacer() string {
@@ -47,6 +72,8 @@ func (s spacerFormatting) LinePostfix() string         { return "" }
 func (s spacerFormatting) AboveTable() string          { return "" }
 func (s spacerFormatting) BelowHeader() string         { return "" }
 func (s spacerFormatting) BetweenRow(index int) string { return "" }
+func (s spacerFormatting) BetweenGroups() string       { return "" }
+func (s spacerFormatting) AboveFooter() string         { return "" }
 func (s spacerFormatting) BelowTable() string          { return "" }
 
 type barFormat struct {
@@ -82,6 +109,54 @@ func (b *barFormat) draw(colSizes []int) string {
 	return bar.String()
 }
 
+// junctionVariants holds the junctions with only the line above, and only
+// the line below, of those drawn between two rows.
+var junctionVariants = map[rune][2]rune{
+	'+':      {'+', '+'},
+	'\u253c': {'\u2534', '\u252c'}, // ┼: ┴ ┬
+	'\u256a': {'\u2567', '\u2564'}, // ╪: ╧ ╤
+}
+
+// drawJoined draws the bar like draw does, but only joins it up with the
+// lines between the columns split above or below it.
+func (b *barFormat) drawJoined(colSizes []int, above []bool, below []bool) string {
+	var bar bytes.Buffer
+
+	bar.WriteString(b.leftCorner)
+	for i, col := range colSizes {
+		if i > 0 {
+			bar.WriteString(b.junction(above[i-1], below[i-1]))
+		}
+		for j := 0; j < col; j++ {
+			bar.WriteRune(b.bar)
+		}
+	}
+	bar.WriteString(b.rightCorner)
+
+	return bar.String()
+}
+
+// junction returns the spacer of the bar, with its junction swapped for one
+// with only the lines going up and down.
+func (b *barFormat) junction(up bool, down bool) string {
+	spacer := []rune(b.spacer)
+	for i, r := range spacer {
+		if r == b.bar {
+			continue
+		}
+		variants, found := junctionVariants[r]
+		switch {
+		case !up && !down:
+			spacer[i] = b.bar
+		case found && !down:
+			spacer[i] = variants[0]
+		case found && !up:
+			spacer[i] = variants[1]
+		}
+	}
+	return string(spacer)
+}
+
 type headerFormatting struct {
 	spacerFormatting
 	barSymbol rune
@@ -97,6 +172,14 @@ func (h *headerFormatting) BelowHeader() string {
 	return format.draw(h.colSizes)
 }
 
+func (h *headerFormatting) BetweenGroups() string {
+	return h.BelowHeader()
+}
+
+func (h *headerFormatting) AboveFooter() string {
+	return h.BelowHeader()
+}
+
 type gridFormatting struct {
 	spacerStr string
 	leftEdge  string
@@ -105,34 +188,59 @@ type gridFormatting struct {
 	top    *barFormat
 	header *barFormat
 	body   *barFormat
+	footer *barFormat
 	bottom *barFormat
 
 	colSizes []int
+	// above and below are the splits passed to Junctions, if any.
+	above []bool
+	below []bool
 }
 
 func (g *gridFormatting) RegisterWidths(colSizes []int) {
 	g.colSizes = colSizes
+	g.above = nil
+	g.below = nil
 }
 
 func (g *gridFormatting) Spacer() string      { return g.spacerStr }
 func (g *gridFormatting) LinePrefix() string  { return g.leftEdge }
 func (g *gridFormatting) LinePostfix() string { return g.rightEdge }
 
+func (g *gridFormatting) Junctions(above []bool, below []bool) {
+	g.above = above
+	g.below = below
+}
+
+func (g *gridFormatting) drawBar(bar *barFormat) string {
+	if g.above == nil || g.below == nil {
+		return bar.draw(g.colSizes)
+	}
+	return bar.drawJoined(g.colSizes, g.above, g.below)
+}
+
 func (g *gridFormatting) AboveTable() string {
-	return g.top.draw(g.colSizes)
+	return g.drawBar(g.top)
 }
 func (g *gridFormatting) BelowHeader() string {
-	return g.header.draw(g.colSizes)
+	return g.drawBar(g.header)
 }
 func (g *gridFormatting) BetweenRow(index int) string {
-	return g.body.draw(g.colSizes)
+	return g.drawBar(g.body)
+}
+func (g *gridFormatting) BetweenGroups() string {
+	return g.drawBar(g.header)
+}
+func (g *gridFormatting) AboveFooter() string {
+	return g.drawBar(g.footer)
 }
 func (g *gridFormatting) BelowTable() string {
-	return g.bottom.draw(g.colSizes)
+	return g.drawBar(g.bottom)
 }
 
-func newGridFormat(left, spacer, right string, top, header, body, bottom *barFormat) *gridFormatting {
-	bars := []*barFormat{top, header, body, bottom}
+func newGridFormat(left, spacer, right string, top, header, body, footer,
+	bottom *barFormat) *gridFormatting {
+	bars := []*barFormat{top, header, body, footer, bottom}
 
 	for _, bar := range bars {
 		bar.leftCorner = leftAlign(
@@ -146,8 +254,8 @@ func newGridFormat(left, spacer, right string, top, header, body, bottom *barFor
 
 	return &gridFormatting{
 		spacer, left, right,
-		top, header, body, bottom,
-		nil,
+		top, header, body, footer, bottom,
+		nil, nil, nil,
 	}
 }
 
@@ -219,6 +327,7 @@ var GridFormat *gridFormatting = newGridFormat(
 	&barFormat{"+", '-', "+", "+"},
 	&barFormat{"+", '=', "+", "+"},
 	&barFormat{"+", '-', "+", "+"},
+	&barFormat{"+", '=', "+", "+"},
 	&barFormat{"+", '-', "+", "+"},
 )
 
@@ -236,5 +345,6 @@ var FancyGridFormat *gridFormatting = newGridFormat(
 	&barFormat{"\u2552", '\u2550', "\u2564", "\u2555"},
 	&barFormat{"\u255e", '\u2550', "\u256a", "\u2561"},
 	&barFormat{"\u251c", '\u2500', "\u253c", "\u2524"},
+	&barFormat{"\u255e", '\u2550', "\u256a", "\u2561"},
 	&barFormat{"\u2558", '\u2550', "\u2567", "\u255b"},
 )
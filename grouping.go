@@ -0,0 +1,139 @@
+package tabulate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// groupRows gathers the rows of table with the same key together, in the
+// order each key is first seen, along with their positions in table.
+func groupRows(table reflect.Value, getter cellGetter) (reflect.Value, []int) {
+	var keys []reflect.Value
+	var groups [][]int
+
+rows:
+	for i := 0; i < table.Len(); i++ {
+		key := underlying(getter(table, i))
+		for g, other := range keys {
+			if sameKey(key, other) {
+				groups[g] = append(groups[g], i)
+				continue rows
+			}
+		}
+		keys = append(keys, key)
+		groups = append(groups, []int{i})
+	}
+
+	// Not nil even without rows, as nil positions are rows left as they are
+	order := make([]int, 0, table.Len())
+	for _, group := range groups {
+		order = append(order, group...)
+	}
+
+	grouped := reflect.MakeSlice(reflect.SliceOf(table.Type().Elem()), 0, len(order))
+	for _, from := range order {
+		grouped = reflect.Append(grouped, table.Index(from))
+	}
+	return grouped, order
+}
+
+// sameKey reports whether two keys of Layout.GroupBy belong to the same
+// group: both are nil, or both are of the same type and written out the
+// same way. Unlike sorting, "file01" and "file1" are different keys.
+func sameKey(key reflect.Value, other reflect.Value) bool {
+	if !key.IsValid() || !other.IsValid() {
+		return key.IsValid() == other.IsValid()
+	}
+	return key.Type() == other.Type() && valueText(key) == valueText(other)
+}
+
+// groupStarts returns the rows starting a group of Layout.GroupBy, in rows
+// of table already gathered by groupRows.
+func groupStarts(table reflect.Value, getter cellGetter) []int {
+	var starts []int
+	for i := 0; i < table.Len(); i++ {
+		if i == 0 || !sameKey(
+			underlying(getter(table, i)), underlying(getter(table, i-1)),
+		) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// groupColumns marks the column of Layout.GroupBy, and returns the rows
+// starting a group, from groupStarts, along with the heading of each group
+// with Layout.GroupHeadings. The repeated keys are only hidden when drawn,
+// as a page may start in the middle of a group, and other views show them
+// all.
+func groupColumns(columns table, layout *Layout, starts []int) (map[int]string, error) {
+	if layout.GroupBy == "" {
+		return nil, nil
+	}
+
+	var key *column
+	for _, col := range columns {
+		if col.name == layout.GroupBy {
+			key = col
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("No column named %q.", layout.GroupBy)
+	}
+	key.groupKey = true
+
+	groups := make(map[int]string)
+	for _, i := range starts {
+		groups[i] = ""
+		if layout.GroupHeadings {
+			// Anchored cells are padded on the right, which is no use here
+			groups[i] = strings.TrimRight(key.column[i], " ")
+		}
+	}
+	return groups, nil
+}
+
+// groupHeading returns the heading of the group row is in.
+func (t table) groupHeading(row int) string {
+	for ; row >= 0; row-- {
+		if heading, found := t[0].groups[row]; found {
+			return heading
+		}
+	}
+	return ""
+}
+
+// tableView returns the columns drawn in TableView, which leaves out the
+// GroupBy column when its values are shown as group headings.
+func (l *Layout) tableView(columns table) (table, error) {
+	if !l.GroupHeadings {
+		return columns, nil
+	}
+
+	var shown table
+	for _, col := range columns {
+		if !col.groupKey {
+			shown = append(shown, col)
+		}
+	}
+	if len(shown) == 0 {
+		return nil, fmt.Errorf("Data must have at least one column.")
+	}
+	return shown, nil
+}
+
+// groupSeparator returns the line drawn between groups of rows, after row
+// index, and whether it is drawn even if empty. Formats that do not
+// implement GroupFormatterInterface get the line below their headers, or
+// else their line between rows.
+func groupSeparator(format TableFormatterInterface, index int) (string, bool) {
+	if groupFormat, ok := format.(GroupFormatterInterface); ok {
+		return groupFormat.BetweenGroups(), true
+	}
+	if bar := format.BelowHeader(); bar != "" {
+		return bar, false
+	}
+	return format.BetweenRow(index), false
+}
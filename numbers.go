@@ -0,0 +1,198 @@
+package tabulate
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Notation determines how a NumberFormat writes out a number.
+type Notation int
+
+const (
+	// DefaultNotation writes out numbers in full, floats with as many
+	// decimals as needed.
+	DefaultNotation Notation = iota
+	// FixedNotation writes out numbers with Precision decimals: 1234.50
+	FixedNotation
+	// ScientificNotation writes out numbers with an exponent: 1.2345e+03
+	ScientificNotation
+	// PercentNotation multiplies numbers by 100 and adds a percent sign:
+	// 0.25 is 25%
+	PercentNotation
+	// SINotation scales numbers by powers of 1000 with SI prefixes: 1.2k,
+	// 3.4M
+	SINotation
+	// IECNotation scales numbers by powers of 1024 with IEC prefixes: with
+	// a Unit of "B", 3.4 GiB
+	IECNotation
+)
+
+// SignDisplay determines when a NumberFormat shows the sign of a number.
+type SignDisplay int
+
+const (
+	// SignAuto only shows the sign of negative numbers.
+	SignAuto SignDisplay = iota
+	// SignAlways shows the sign of every number, zero included.
+	SignAlways
+	// SignExceptZero shows the sign of every number but zero.
+	SignExceptZero
+	// SignNever never shows the sign.
+	SignNever
+)
+
+// NumberFormat determines how the ints and floats of a column are written
+// out. The zero value writes them out as is.
+type NumberFormat struct {
+	Notation Notation
+	// Precision is the number of decimals shown by every notation but
+	// DefaultNotation. A negative Precision shows as many as needed.
+	Precision int
+	// Thousands separates every group of three digits left of the decimal
+	// point, for example with ",".
+	Thousands string
+	Sign      SignDisplay
+	// Unit is added after SI and IEC prefixes, and separated from the
+	// number by a space.
+	Unit string
+}
+
+var siPrefixes = []string{"", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+var siSmallPrefixes = []string{"", "m", "µ", "n", "p"}
+var iecPrefixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+
+// exact reports whether ints can be written out without going through a
+// float, which keeps the full range of int64 and uint64.
+func (f *NumberFormat) exact() bool {
+	return f.Notation == DefaultNotation || f.Notation == FixedNotation
+}
+
+func (f *NumberFormat) formatInt(value int64) string {
+	if !f.exact() {
+		return f.formatFloat(float64(value), 64)
+	}
+	if value < 0 {
+		// Going through value+1 so math.MinInt64 does not overflow
+		return f.formatDigits(uint64(-(value+1))+1, true)
+	}
+	return f.formatDigits(uint64(value), false)
+}
+
+func (f *NumberFormat) formatUint(value uint64) string {
+	if !f.exact() {
+		return f.formatFloat(float64(value), 64)
+	}
+	return f.formatDigits(value, false)
+}
+
+func (f *NumberFormat) formatDigits(abs uint64, negative bool) string {
+	digits := strconv.FormatUint(abs, 10)
+	if f.Notation == FixedNotation && f.Precision > 0 {
+		digits += "." + strings.Repeat("0", f.Precision)
+	}
+	return f.sign(f.separate(digits), negative, abs == 0)
+}
+
+// formatFloat writes out a float with the given bit size (32 or 64).
+func (f *NumberFormat) formatFloat(value float64, bits int) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.FormatFloat(value, 'f', -1, bits)
+	}
+
+	negative := value < 0
+	abs := math.Abs(value)
+	suffix := ""
+	var digits string
+
+	switch f.Notation {
+	case FixedNotation:
+		digits = strconv.FormatFloat(abs, 'f', f.Precision, bits)
+	case ScientificNotation:
+		digits = strconv.FormatFloat(abs, 'e', f.Precision, bits)
+	case PercentNotation:
+		digits = strconv.FormatFloat(abs*100, 'f', f.Precision, 64)
+		suffix = "%"
+	case SINotation:
+		digits, suffix = f.scale(abs, 1000, siPrefixes, siSmallPrefixes, bits)
+	case IECNotation:
+		digits, suffix = f.scale(abs, 1024, iecPrefixes, nil, bits)
+	default:
+		digits = strconv.FormatFloat(abs, 'f', -1, bits)
+	}
+
+	if f.Notation != ScientificNotation {
+		digits = f.separate(digits)
+	}
+	return f.sign(digits+suffix, negative, abs == 0)
+}
+
+// scale divides abs by base until it is below base, and returns it along
+// with the matching prefix (and Unit). Numbers below one get scaled up
+// with small prefixes instead, when there are any.
+func (f *NumberFormat) scale(abs float64, base float64, prefixes []string,
+	small []string, bits int) (string, string) {
+
+	power := 0
+	if abs != 0 {
+		for abs >= base && power < len(prefixes)-1 {
+			abs /= base
+			power++
+		}
+		for abs < 1 && -power < len(small)-1 {
+			abs *= base
+			power--
+		}
+	}
+
+	digits := strconv.FormatFloat(abs, 'f', f.Precision, bits)
+	if rounded, _ := strconv.ParseFloat(digits, 64); rounded >= base &&
+		power < len(prefixes)-1 {
+		// Rounding up reached the next prefix, as with 999.96 to 1000.0
+		power++
+		digits = strconv.FormatFloat(abs/base, 'f', f.Precision, bits)
+	}
+
+	prefix := ""
+	if power >= 0 {
+		prefix = prefixes[power]
+	} else {
+		prefix = small[-power]
+	}
+	if f.Unit != "" {
+		return digits, " " + prefix + f.Unit
+	}
+	return digits, prefix
+}
+
+// separate adds the Thousands separator to the integer part of digits.
+func (f *NumberFormat) separate(digits string) string {
+	if f.Thousands == "" {
+		return digits
+	}
+
+	integer, fraction := digits, ""
+	if decimal := strings.Index(digits, "."); decimal > -1 {
+		integer, fraction = digits[:decimal], digits[decimal:]
+	}
+
+	var grouped []string
+	for len(integer) > 3 {
+		grouped = append([]string{integer[len(integer)-3:]}, grouped...)
+		integer = integer[:len(integer)-3]
+	}
+	grouped = append([]string{integer}, grouped...)
+	return strings.Join(grouped, f.Thousands) + fraction
+}
+
+func (f *NumberFormat) sign(digits string, negative bool, zero bool) string {
+	switch {
+	case f.Sign == SignNever:
+		return digits
+	case negative:
+		return "-" + digits
+	case f.Sign == SignAlways, f.Sign == SignExceptZero && !zero:
+		return "+" + digits
+	}
+	return digits
+}
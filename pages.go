@@ -0,0 +1,130 @@
+package tabulate
+
+import (
+	"io"
+	"strings"
+)
+
+// Paging splits a table into pages, for printed reports and long terminal
+// output. Every page is drawn as a table of its own, with the headers and
+// the format's lines around it, but every page gets the same column widths.
+// The footers only go on the last page.
+type Paging struct {
+	// Rows is the most rows of data a page holds.
+	Rows int
+	// Lines is the most lines the rows of a page take up, counting every
+	// line of cells with line breaks in them, the lines the format draws
+	// between rows and the group headings. A row taller than Lines gets a
+	// page of its own.
+	Lines int
+	// Caption returns the line written below every page, if set, from the
+	// number of the page (counting from 1) and the number of pages.
+	Caption func(page int, pages int) string
+}
+
+// Paginate tabulates data like Tabulate, but split into pages as paging
+// says. Without Rows or Lines, the whole table is a single page.
+func Paginate(data interface{}, layout *Layout, paging Paging) ([]string, error) {
+	columns, err := buildTable(data, layout)
+	if err == nil {
+		columns, err = layout.tableView(columns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	format := layout.format()
+	showHeaders := !layout.HideHeaders
+	columns.prepare(format, showHeaders)
+
+	breaks := columns.pageBreaks(format, paging)
+	pages := make([]string, len(breaks)-1)
+	for i := range pages {
+		last := i == len(pages)-1
+		pages[i] = columns.drawRows(
+			format, showHeaders, breaks[i], breaks[i+1], last,
+		)
+		if paging.Caption != nil {
+			pages[i] += paging.Caption(i+1, len(pages)) + "\n"
+		}
+	}
+	return pages, nil
+}
+
+// WritePages writes the pages of Paginate to w, one after the other.
+func WritePages(w io.Writer, data interface{}, layout *Layout,
+	paging Paging) error {
+
+	pages, err := Paginate(data, layout, paging)
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if _, err := io.WriteString(w, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageBreaks returns the index of the first row of every page, followed by
+// the number of rows. There is always at least one page, even if empty.
+func (t table) pageBreaks(format TableFormatterInterface, paging Paging) []int {
+	length := len(t[0].column)
+	breaks := []int{0}
+	rows, lines := 0, 0
+
+	for i := 0; i < length; i++ {
+		height := t.rowHeight(i)
+		heading, groupStart := t[0].groups[i]
+		if heading != "" {
+			height += 1 + lineCount(format.BetweenRow(i))
+		}
+		between := 0
+		if rows > 0 && groupStart {
+			bar, always := groupSeparator(format, i-1)
+			between = lineCount(bar)
+			if always && bar == "" {
+				between = 1
+			}
+		} else if rows > 0 {
+			between = lineCount(format.BetweenRow(i - 1))
+		}
+
+		if paging.Rows > 0 && rows == paging.Rows ||
+			paging.Lines > 0 && rows > 0 &&
+				lines+between+height > paging.Lines {
+			// The line between rows is not drawn across pages.
+			breaks = append(breaks, i)
+			rows, lines, between = 0, 0, 0
+			// but the heading of a group is drawn again.
+			if !groupStart {
+				if heading := t.groupHeading(i); heading != "" {
+					height += 1 + lineCount(format.BetweenRow(i))
+				}
+			}
+		}
+		rows++
+		lines += between + height
+	}
+	return append(breaks, length)
+}
+
+// rowHeight is the number of lines row i takes up.
+func (t table) rowHeight(i int) int {
+	height := 1
+	for _, col := range t {
+		if lines := strings.Count(col.column[i], "\n") + 1; lines > height {
+			height = lines
+		}
+	}
+	return height
+}
+
+// lineCount is the number of lines text takes up, none if empty.
+func lineCount(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
@@ -0,0 +1,134 @@
+package tabulate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// View picks how Tabulate lays out the records of a table.
+type View int
+
+const (
+	// TableView draws a record per row, and a field per column. It
+	// switches to ExpandedView when wider than Layout.ExpandWidth, if set.
+	TableView View = iota
+	// ExpandedView writes each record out as a block of "header | value"
+	// lines, under a "-[ RECORD 1 ]" line, like psql's expanded display:
+	//    -[ RECORD 1 ]--
+	//    name   | Apple
+	//    amount | 15
+	//    -[ RECORD 2 ]--
+	//    name   | Orange
+	//    amount | 1
+	// Layout.Format and the footers are not used.
+	ExpandedView
+	// TransposedView swaps the rows and the columns of the table around,
+	// drawing the headers down its first column and a record per column.
+	// The footers are not used.
+	TransposedView
+)
+
+// view returns the view the table is drawn with.
+func (l *Layout) view(t table) (View, error) {
+	if l.View != TableView || l.ExpandWidth <= 0 {
+		return l.View, nil
+	}
+	shown, err := l.tableView(t)
+	if err != nil {
+		return TableView, err
+	}
+	widths := shown.fitWidths(l.format(), !l.HideHeaders)
+	if blockLength(l.format(), widths) > l.ExpandWidth {
+		return ExpandedView, nil
+	}
+	return TableView, nil
+}
+
+// fieldName is what a column is called in the expanded and transposed
+// views, which show it even if the headers are hidden.
+func (c *column) fieldName() string {
+	if c.header != "" {
+		return c.header
+	}
+	return c.name
+}
+
+// drawExpanded draws a table that has not been aligned in ExpandedView.
+func (t table) drawExpanded() string {
+	var output strings.Builder
+
+	nameWidth := 0
+	for _, col := range t {
+		if utf8Len(col.fieldName()) > nameWidth {
+			nameWidth = utf8Len(col.fieldName())
+		}
+	}
+
+	// The rules of every record are as wide, like psql's
+	valueWidth := 0
+	for rowI := 0; rowI < len(t[0].column); rowI++ {
+		for i, span := range t.rowSpans(rowI) {
+			if span == 0 {
+				continue
+			}
+			// Anchored cells are padded on the right, which is no use here
+			cell := strings.TrimRight(t[i].column[rowI], " ")
+			if width := utf8Len(cell); width > valueWidth {
+				valueWidth = width
+			}
+		}
+	}
+	bar := strings.Repeat("-", nameWidth+1) + "+" +
+		strings.Repeat("-", valueWidth+1)
+
+	for rowI := 0; rowI < len(t[0].column); rowI++ {
+		label := fmt.Sprintf("-[ RECORD %d ]", rowI+1)
+		if utf8Len(label) < utf8Len(bar) {
+			label += string([]rune(bar)[utf8Len(label):])
+		}
+		output.WriteString(label + "\n")
+
+		for i, span := range t.rowSpans(rowI) {
+			if span == 0 {
+				// Covered by a Span, which is written out in its place
+				continue
+			}
+			col := t[i]
+			value := strings.TrimRight(col.column[rowI], " ")
+			line := leftAlign(col.fieldName(), ' ', nameWidth) + " | " + value
+			output.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+	return output.String()
+}
+
+// transpose returns a table that has not been aligned with its rows and
+// columns swapped around, its first column holding the field names.
+func (t table) transpose() table {
+	names := &column{}
+	for _, col := range t {
+		names.column = append(names.column, col.fieldName())
+	}
+	transposed := table{names}
+
+	for rowI := 0; rowI < len(t[0].column); rowI++ {
+		record := &column{}
+		spans := t.rowSpans(rowI)
+		for i, col := range t {
+			cell := strings.TrimRight(col.column[rowI], " ")
+			if spans[i] == 0 {
+				// Covered by a Span, and left blank to keep the fields
+				// lined up with their names
+				cell = ""
+			}
+			record.column = append(record.column, cell)
+			align := AlignDefault
+			if rowI < len(col.aligns) {
+				align = col.aligns[rowI]
+			}
+			record.aligns = append(record.aligns, align)
+		}
+		transposed = append(transposed, record)
+	}
+	return transposed
+}
@@ -1,18 +1,73 @@
 package tabulate
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
-func intToString(integer reflect.Value) string {
-	return strconv.Itoa(int(integer.Int()))
+// Cell can be implemented by the values of a column to write themselves
+// out, and to pick how they are aligned within the column. It takes
+// precedence over every other way of writing out a value, short of a Verb or
+// a Formatter set on the column.
+type Cell interface {
+	Cell() (text string, align Alignment)
 }
 
-func floatToString(floating reflect.Value) string {
-	return strconv.FormatFloat(floating.Float(), 'f', -1, floating.Type().Bits())
+var cellInterface = reflect.TypeOf((*Cell)(nil)).Elem()
+var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+var textMarshalerInterface = reflect.TypeOf(
+	(*encoding.TextMarshaler)(nil),
+).Elem()
+
+// TypedFormatter wraps a formatter of values of type T, for use as a
+// ColumnFormat's Formatter. Cells not holding a T are written out with
+// fmt.Sprint.
+func TypedFormatter[T any](format func(T) string) func(interface{}) string {
+	return func(value interface{}) string {
+		if typed, ok := value.(T); ok {
+			return format(typed)
+		}
+		return fmt.Sprint(value)
+	}
+}
+
+// isNil reports whether value is a nil pointer or interface.
+func isNil(value reflect.Value) bool {
+	return (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) &&
+		value.IsNil()
+}
+
+// exportValue returns what value holds. Values read out of unexported
+// struct fields cannot be handed out as is, so the basic kinds among them
+// get copied, and the rest come out as nil.
+func exportValue(value reflect.Value) interface{} {
+	if value.CanInterface() {
+		return value.Interface()
+	}
+
+	exported := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Bool:
+		exported.SetBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		exported.SetInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		exported.SetUint(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		exported.SetFloat(value.Float())
+	case reflect.Complex64, reflect.Complex128:
+		exported.SetComplex(value.Complex())
+	case reflect.String:
+		exported.SetString(value.String())
+	default:
+		return nil
+	}
+	return exported.Interface()
 }
 
 func boolToString(boolean reflect.Value) string {
@@ -32,65 +87,324 @@ func callString(value reflect.Value) string {
 	return res[0].String()
 }
 
-func guessCaster(cellType reflect.Type) (func(reflect.Value) string, error) {
+func callCell(value reflect.Value) string {
+	res := value.MethodByName("Cell").Call(nil)
+	return res[0].String()
+}
+
+func callError(value reflect.Value) string {
+	res := value.MethodByName("Error").Call(nil)
+	return res[0].String()
+}
+
+func callMarshalText(value reflect.Value) string {
+	res := value.MethodByName("MarshalText").Call(nil)
+	if !res[1].IsNil() {
+		return res[1].Interface().(error).Error()
+	}
+	return string(res[0].Bytes())
+}
+
+// methodCaster returns a caster calling the method cellType has to write
+// itself out, or nil if it has none. In order of preference, those are
+// Cell, Error, String and MarshalText.
+func methodCaster(cellType reflect.Type) func(reflect.Value) string {
+	switch {
+	case cellType.Implements(cellInterface):
+		return callCell
+	case cellType.Implements(errorInterface):
+		return callError
+	}
+	if _, found := cellType.MethodByName("String"); found {
+		return callString
+	}
+	if cellType.Implements(textMarshalerInterface) {
+		return callMarshalText
+	}
+	return nil
+}
+
+// cellText calls the Cell method of value, or of what it points to or
+// holds, if it has one.
+func cellText(value reflect.Value) (string, Alignment, bool) {
+	for !value.Type().Implements(cellInterface) {
+		if value.Kind() != reflect.Ptr && value.Kind() != reflect.Interface {
+			return "", AlignDefault, false
+		}
+		if value.IsNil() {
+			return "", AlignDefault, false
+		}
+		value = value.Elem()
+	}
+
+	if !value.CanInterface() ||
+		(value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) &&
+			value.IsNil() {
+		return "", AlignDefault, false
+	}
+	text, align := value.Interface().(Cell).Cell()
+	return text, align, true
+}
+
+func guessCaster(cellType reflect.Type, format *ColumnFormat,
+	missing string) (func(reflect.Value) string, error) {
+	method := methodCaster(cellType)
+	number := &format.Number
+
+	if format.Formatter != nil {
+		return func(value reflect.Value) string {
+			if isNil(value) {
+				return missing
+			}
+			return format.Formatter(exportValue(value))
+		}, nil
+	}
+	if format.Verb != "" {
+		return func(value reflect.Value) string {
+			if isNil(value) {
+				return missing
+			}
+			// fmt looks through the reflect.Value, so a fmt.Formatter
+			// gets to handle the verb itself.
+			return fmt.Sprintf(format.Verb, value)
+		}, nil
+	}
+	if caster := timeCaster(cellType, &format.Time, missing); caster != nil {
+		return caster, nil
+	}
+
 	switch cellType.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
-		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
-		reflect.Uint32, reflect.Uint64:
-		return intToString, nil
+		reflect.Int64:
+		return func(integer reflect.Value) string {
+			return number.formatInt(integer.Int())
+		}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return func(integer reflect.Value) string {
+			return number.formatUint(integer.Uint())
+		}, nil
 
 	case reflect.Float32, reflect.Float64:
-		return floatToString, nil
+		return func(floating reflect.Value) string {
+			return number.formatFloat(
+				floating.Float(), floating.Type().Bits(),
+			)
+		}, nil
 
 	case reflect.Bool:
 		return boolToString, nil
 
 	case reflect.String:
 		return stringToString, nil
+
+	case reflect.Interface:
+		if method != nil {
+			break
+		}
+		// Without a static type to go on, cast each cell by what it holds.
+		return func(value reflect.Value) string {
+			if value.IsNil() {
+				return missing
+			}
+			caster, err := guessCaster(value.Elem().Type(), format, missing)
+			if err != nil {
+				return fmt.Sprint(value.Elem())
+			}
+			return caster(value.Elem())
+		}, nil
+
+	case reflect.Ptr:
+		if method != nil && timeCaster(cellType.Elem(), &format.Time, missing) == nil {
+			break
+		}
+		// Pointers to anything else are shown as what they point to.
+		caster, err := guessCaster(cellType.Elem(), format, missing)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) string {
+			if value.IsNil() {
+				return missing
+			}
+			return caster(value.Elem())
+		}, nil
 	}
 
-	_, found := cellType.MethodByName("String")
-	if !found {
+	if method == nil {
 		return nil, fmt.Errorf(
 			"Column must either contain an int, a float, a bool, a string " +
-				"or something implementing the fmt.Stringer interface.")
+				"or something implementing the fmt.Stringer, error, " +
+				"encoding.TextMarshaler or Cell interface.")
 	}
 
-	return callString, nil
+	// Methods cannot be called on values read out of unexported struct
+	// fields, which are shown as missing instead.
+	if cellType.Kind() == reflect.Ptr || cellType.Kind() == reflect.Interface {
+		return func(value reflect.Value) string {
+			if value.IsNil() || !value.CanInterface() {
+				return missing
+			}
+			return method(value)
+		}, nil
+	}
+	return func(value reflect.Value) string {
+		if !value.CanInterface() {
+			return missing
+		}
+		return method(value)
+	}, nil
 }
 
-func alignFloats(floats []string) {
-	maxRight := 0
+// isMixedNumeric reports whether a column of interface cells only holds
+// numbers, at least one of which is a float. Nil cells are skipped.
+func isMixedNumeric(cellType reflect.Type, cells []reflect.Value) bool {
+	if cellType.Kind() != reflect.Interface {
+		return false
+	}
 
-	for _, number := range floats {
-		decimal := strings.Index(number, ".")
-		right := 0
+	foundFloat := false
+	for _, cell := range cells {
+		if !cell.IsValid() || cell.IsNil() {
+			continue
+		}
+		switch cell.Elem().Kind() {
+		case reflect.Float32, reflect.Float64:
+			foundFloat = true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return false
+		}
+	}
+	return foundFloat
+}
+
+// isFloat reports whether cellType is a float, or a pointer to one.
+func isFloat(cellType reflect.Type) bool {
+	for cellType.Kind() == reflect.Ptr {
+		cellType = cellType.Elem()
+	}
+	return cellType.Kind() == reflect.Float32 ||
+		cellType.Kind() == reflect.Float64
+}
+
+// isText reports whether cellType is a string, a pointer to one, or an
+// interface that could hold one.
+func isText(cellType reflect.Type) bool {
+	for cellType.Kind() == reflect.Ptr {
+		cellType = cellType.Elem()
+	}
+	return cellType.Kind() == reflect.String ||
+		cellType.Kind() == reflect.Interface
+}
+
+// parseNumbers checks whether every cell of a text column, bar the missing
+// ones, holds a number. If so, it also reports whether any of them is a
+// float, and writes the cells out again with number unless it is the zero
+// NumberFormat (which would lose the original text for nothing).
+func parseNumbers(cells []string, number *NumberFormat,
+	missing string) (numeric bool, floats bool) {
 
-		if decimal > -1 {
-			// 123.45 -> 6 - 3 = 3, 3
-			// .1 -> 2 - 0 = 2, 0
-			right = len(number) - decimal - 1
-		} else {
-			// 12345 -> 0, 5
-			right = 0
+	parsed := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		text := strings.TrimSpace(cell)
+		if cell == missing {
+			continue
+		}
+		// Keeps out words that parse as floats, like "nan" or "Inf".
+		if !strings.ContainsAny(text, "0123456789") {
+			return false, false
 		}
 
+		if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+			parsed[i] = integer
+			continue
+		}
+		floating, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return false, false
+		}
+		parsed[i] = floating
+		floats = true
+	}
+
+	if *number != (NumberFormat{}) {
+		for i, value := range parsed {
+			switch value := value.(type) {
+			case int64:
+				cells[i] = number.formatInt(value)
+			case float64:
+				cells[i] = number.formatFloat(value, 64)
+			}
+		}
+	}
+	return true, floats
+}
+
+// anchorAt returns the position, in runes, that cell gets aligned on. That
+// is where anchor first appears, or the end of the cell if it never does.
+// For the "." anchor, a cell without a decimal point is instead anchored
+// right after its leading number, so 12, 1e+21 and 3 GiB all line up with
+// 1.5. Cells without a number, like NaN or +Inf, are anchored at their end.
+func anchorAt(cell string, anchor string) int {
+	if index := strings.Index(cell, anchor); index > -1 {
+		return utf8Len(cell[:index])
+	}
+	if anchor != "." {
+		return utf8Len(cell)
+	}
+
+	end := 0
+	for i, char := range cell {
+		switch {
+		case char >= '0' && char <= '9':
+			end = i + 1
+		case i == 0 && (char == '-' || char == '+'):
+		case end > 0 && char == ',':
+		default:
+			if end > 0 {
+				return utf8Len(cell[:end])
+			}
+			return utf8Len(cell)
+		}
+	}
+	return utf8Len(cell)
+}
+
+// alignOn pads cells on the right so that their anchors line up, once the
+// cells are right aligned.
+func alignOn(cells []string, anchor string) {
+	maxRight := 0
+
+	for _, cell := range cells {
+		// 123.45 -> 6 - 3 = 3
+		// 12345 -> 5 - 5 = 0
+		right := utf8Len(cell) - anchorAt(cell, anchor)
 		if maxRight < right {
 			maxRight = right
 		}
 	}
 
-	for i, number := range floats {
-		decimal := strings.Index(number, ".")
+	for i, cell := range cells {
+		right := utf8Len(cell) - anchorAt(cell, anchor)
+		cells[i] = padToken(cell, ' ', 0, maxRight-right)
+	}
+}
 
-		// 12345 in 6 = 6 + 5 + 1 = 12
-		// 1.1 in 6 -> 6 + 1 + 1 = 8
-		// .1234 in 4 -> 4 + 0 + 1 = 5
-		// .1234 in 6 -> 6 + 0 + 1 = 7
-		if decimal == -1 {
-			decimal = len(number)
-		}
+// castValue writes out a single value with format, the way a column of its
+// type would be.
+func castValue(value interface{}, format *ColumnFormat,
+	missing string) (string, error) {
 
-		floats[i] = leftAlign(number, ' ', maxRight+decimal+1)
+	if value == nil {
+		return missing, nil
+	}
+	caster, err := guessCaster(reflect.TypeOf(value), format, missing)
+	if err != nil {
+		return "", err
 	}
+	return caster(reflect.ValueOf(value)), nil
 }
@@ -0,0 +1,215 @@
+package tabulate
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortKey sorts the rows of a table by the values of one of its columns.
+type SortKey struct {
+	// Name is the name of the column, as used by Layout.Columns.
+	Name       string
+	Descending bool
+	// NilsFirst puts nil and missing values ahead of all others, instead of
+	// after them, whichever way the rows are sorted.
+	NilsFirst bool
+}
+
+// cellGetter reads the value of a column out of row i of a table.
+type cellGetter func(table reflect.Value, i int) reflect.Value
+
+// sortRows returns the rows of table in the order given by keys, along
+// with their positions in table. The sort is stable, so rows with equal
+// keys stay in the order of the data.
+func sortRows(table reflect.Value, keys []SortKey,
+	getters []cellGetter) (reflect.Value, []int) {
+
+	length := table.Len()
+	values := make([][]reflect.Value, len(keys))
+	for k := range keys {
+		values[k] = make([]reflect.Value, length)
+		for i := 0; i < length; i++ {
+			values[k][i] = underlying(getters[k](table, i))
+		}
+	}
+
+	order := make([]int, length)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		for k, key := range keys {
+			left, right := values[k][order[a]], values[k][order[b]]
+
+			switch {
+			case !left.IsValid() && !right.IsValid():
+				continue
+			case !left.IsValid():
+				return key.NilsFirst
+			case !right.IsValid():
+				return !key.NilsFirst
+			}
+
+			cmp := compareValues(left, right)
+			if key.Descending {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	sorted := reflect.MakeSlice(reflect.SliceOf(table.Type().Elem()), length, length)
+	for to, from := range order {
+		sorted.Index(to).Set(table.Index(from))
+	}
+	return sorted, order
+}
+
+// underlying follows pointers and interfaces to the value they hold, which
+// is invalid for nil ones.
+func underlying(value reflect.Value) reflect.Value {
+	for value.IsValid() &&
+		(value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// valueRank orders values of different sorts: numbers go before bools,
+// which go before times, and then everything else.
+func valueRank(value reflect.Value) int {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64:
+		return 0
+	case reflect.Bool:
+		return 1
+	}
+	if value.Type() == timeType {
+		return 2
+	}
+	return 3
+}
+
+// compareValues returns -1, 0 or 1 as left is less than, equal to or more
+// than right. Numbers compare numerically, times chronologically and
+// everything else by its text, naturally.
+func compareValues(left reflect.Value, right reflect.Value) int {
+	leftRank, rightRank := valueRank(left), valueRank(right)
+	if leftRank != rightRank {
+		return compareInts(leftRank, rightRank)
+	}
+
+	switch leftRank {
+	case 0:
+		return toBig(left).Cmp(toBig(right))
+	case 1:
+		return compareInts(boolRank(left.Bool()), boolRank(right.Bool()))
+	case 2:
+		if left.CanInterface() && right.CanInterface() {
+			return left.Interface().(time.Time).Compare(
+				right.Interface().(time.Time),
+			)
+		}
+	}
+	return compareNatural(valueText(left), valueText(right))
+}
+
+func compareInts(left int, right int) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+func boolRank(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// toBig holds any int, uint or float without losing precision, so that the
+// full ranges of int64 and uint64 compare correctly with one another.
+func toBig(value reflect.Value) *big.Float {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return new(big.Float).SetInt64(value.Int())
+	case reflect.Float32, reflect.Float64:
+		if f := value.Float(); !math.IsNaN(f) {
+			return new(big.Float).SetFloat64(f)
+		}
+		// NaN, which big.Float does not hold, sorts as zero.
+		return new(big.Float)
+	}
+	return new(big.Float).SetUint64(value.Uint())
+}
+
+// valueText is the text values without an order of their own get compared
+// by: a string as is, or what String returns for Stringers.
+func valueText(value reflect.Value) string {
+	if value.Kind() == reflect.String {
+		return value.String()
+	}
+	if method := methodCaster(value.Type()); method != nil &&
+		value.CanInterface() {
+		return method(value)
+	}
+	return fmt.Sprint(value)
+}
+
+// compareNatural compares text the way people would, with runs of digits
+// compared by their numeric value, so "file10" goes after "file9".
+func compareNatural(left string, right string) int {
+	for left != "" && right != "" {
+		leftChunk, leftDigits := nextChunk(left)
+		rightChunk, rightDigits := nextChunk(right)
+		left, right = left[len(leftChunk):], right[len(rightChunk):]
+
+		if leftDigits && rightDigits {
+			leftNumber := strings.TrimLeft(leftChunk, "0")
+			rightNumber := strings.TrimLeft(rightChunk, "0")
+			if cmp := compareInts(len(leftNumber), len(rightNumber)); cmp != 0 {
+				return cmp
+			}
+			if cmp := strings.Compare(leftNumber, rightNumber); cmp != 0 {
+				return cmp
+			}
+			continue
+		}
+		if cmp := strings.Compare(leftChunk, rightChunk); cmp != 0 {
+			return cmp
+		}
+	}
+	return compareInts(len(left), len(right))
+}
+
+// nextChunk returns the leading run of digits or non-digits of text, and
+// whether it is made of digits.
+func nextChunk(text string) (string, bool) {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	digits := isDigit(text[0])
+
+	end := 1
+	for end < len(text) && isDigit(text[end]) == digits {
+		end++
+	}
+	return text[:end], digits
+}
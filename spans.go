@@ -0,0 +1,79 @@
+package tabulate
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Span is a cell spanning several columns, such as a heading over a few
+// columns or "N/A" across a section of a row. Put it in the data in place
+// of the first cell it covers, in a field or slice of interface{} (or of
+// Span). The cells it covers are not shown, whatever they hold.
+type Span struct {
+	// Value is written out with the format of the column the Span is in.
+	Value interface{}
+	// Columns is how many columns the Span covers, counting its own. It
+	// stops at the last column of the table.
+	Columns int
+	// Align picks where the Value sits across the columns.
+	Align Alignment
+}
+
+// String writes out the Value of the span, which is what sorting and
+// filtering compare.
+func (s Span) String() string {
+	return fmt.Sprint(s.Value)
+}
+
+var spanType = reflect.TypeOf(Span{})
+
+// spanOf returns the Span held in cell, if it holds one.
+func spanOf(cell reflect.Value) (Span, bool) {
+	cell = underlying(cell)
+	if !cell.IsValid() || cell.Type() != spanType {
+		return Span{}, false
+	}
+	if cell.CanInterface() {
+		return cell.Interface().(Span), true
+	}
+
+	// Spans in unexported fields can only be read a field at a time
+	span := Span{
+		Columns: int(cell.FieldByName("Columns").Int()),
+		Align:   Alignment(cell.FieldByName("Align").Int()),
+	}
+	if value := underlying(cell.FieldByName("Value")); value.IsValid() {
+		span.Value = exportValue(value)
+	}
+	return span, true
+}
+
+// rowSpans returns how many columns the cell in each column of a row
+// covers: 1 for most, more for a Span and none for the cells it covers.
+// Rows below 0, like the headers and the footers, have no spans.
+func (t table) rowSpans(row int) []int {
+	spans := make([]int, len(t))
+	for i := 0; i < len(t); {
+		span := 1
+		if row >= 0 && t[i].spans[row] > 1 {
+			span = t[i].spans[row]
+		}
+		if i+span > len(t) {
+			span = len(t) - i
+		}
+		spans[i] = span
+		i += span
+	}
+	return spans
+}
+
+// splits returns whether each gap between two columns of a row is split
+// by a line, which it is unless a Span covers it.
+func (t table) splits(row int) []bool {
+	spans := t.rowSpans(row)
+	splits := make([]bool, len(t)-1)
+	for i := range splits {
+		splits[i] = spans[i+1] > 0
+	}
+	return splits
+}
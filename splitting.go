@@ -0,0 +1,146 @@
+package tabulate
+
+import (
+	"fmt"
+)
+
+// Splitting splits a table wider than Width into blocks of columns, drawn
+// one below the other, the way data frames are printed when too wide for
+// the screen.
+type Splitting struct {
+	// Width is the most characters a line of a block takes up. Tables
+	// that fit, or any table if Width is not above 0, are not split.
+	Width int
+	// Keys names the columns repeated at the start of every block, such
+	// as Name, so the rows of every block can be told apart. The names
+	// are the ones Layout.Columns uses.
+	Keys []string
+	// Padding goes between blocks, as it does for CombineVertical, which
+	// also pads narrower blocks out to the width of the first.
+	Padding string
+}
+
+// TabulateSplit tabulates data like Tabulate, but split into blocks of
+// columns as splitting says. Every block gets at least one column besides
+// the keys, even if that makes it wider than Width, and a table of nothing
+// but keys is not split. Spans stop at the edge of their block.
+func TabulateSplit(data interface{}, layout *Layout,
+	splitting Splitting) (string, error) {
+
+	columns, err := buildTable(data, layout)
+	if err == nil {
+		columns, err = layout.tableView(columns)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var keys, rest []int
+	for _, name := range splitting.Keys {
+		found := false
+		for i, col := range columns {
+			if col.name == name {
+				keys = append(keys, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("No column named %q.", name)
+		}
+	}
+	for i := range columns {
+		if !containsIndex(keys, i) {
+			rest = append(rest, i)
+		}
+	}
+
+	format := layout.format()
+	showHeaders := !layout.HideHeaders
+	widths := columns.fitWidths(format, showHeaders)
+	if splitting.Width <= 0 || len(rest) == 0 ||
+		blockLength(format, widths) <= splitting.Width {
+		return columns.draw(format, showHeaders), nil
+	}
+
+	var blocks []string
+	for start := 0; ; {
+		end := start + 1
+		for end < len(rest) {
+			indexes := append(append([]int{}, keys...), rest[start:end+1]...)
+			blockWidths := columns.block(indexes).fitWidths(format, showHeaders)
+			if blockLength(format, blockWidths) > splitting.Width {
+				break
+			}
+			end++
+		}
+
+		indexes := append(append([]int{}, keys...), rest[start:end]...)
+		blocks = append(blocks, columns.block(indexes).draw(format, showHeaders))
+		if start = end; start >= len(rest) {
+			break
+		}
+	}
+
+	stacked := blocks[0]
+	for _, block := range blocks[1:] {
+		stacked = CombineVertical(stacked, block, splitting.Padding)
+	}
+	return stacked, nil
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, other := range indexes {
+		if other == index {
+			return true
+		}
+	}
+	return false
+}
+
+// block returns a copy of the columns of t at the given indexes, not yet
+// aligned, as a table of its own. Spans are cut short where the columns
+// they cover are not next to them in the block, and the cells they cover
+// are left blank wherever they still show.
+func (t table) block(indexes []int) table {
+	block := make(table, len(indexes))
+	for i, index := range indexes {
+		col := *t[index]
+		col.column = append([]string{}, col.column...)
+		col.footer = append([]string{}, col.footer...)
+		col.spans = make(map[int]int)
+		block[i] = &col
+	}
+
+	for row := 0; len(t) > 0 && row < len(t[0].column); row++ {
+		spans := t.rowSpans(row)
+		for i, index := range indexes {
+			if spans[index] == 0 {
+				block[i].column[row] = ""
+				continue
+			}
+			covered := 1
+			for i+covered < len(indexes) && covered < spans[index] &&
+				indexes[i+covered] == index+covered {
+				covered++
+			}
+			if covered > 1 {
+				block[i].spans[row] = covered
+			}
+		}
+	}
+	return block
+}
+
+// blockLength is how many characters a line of a table with columns of the
+// given widths takes up in format.
+func blockLength(format TableFormatterInterface, widths []int) int {
+	length := utf8Len(format.LinePrefix()) + utf8Len(format.LinePostfix())
+	for i, width := range widths {
+		if i > 0 {
+			length += utf8Len(format.Spacer())
+		}
+		length += width
+	}
+	return length
+}
@@ -0,0 +1,165 @@
+package tabulate
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structField is a column pulled out of a struct, possibly from within an
+// embedded or a nested struct.
+type structField struct {
+	name     string
+	path     []int
+	cellType reflect.Type
+	// tagged is whether the name comes from a tabulate tag.
+	tagged bool
+}
+
+// parseTag splits a `tabulate:"name,option"` struct tag into its name and
+// whether the flatten option was given.
+func parseTag(field reflect.StructField) (name string, flatten bool) {
+	tag := strings.Split(field.Tag.Get("tabulate"), ",")
+	for _, option := range tag[1:] {
+		if option == "flatten" {
+			flatten = true
+		}
+	}
+	return tag[0], flatten
+}
+
+// isStringer reports whether the struct (or a pointer to it) has a method
+// to write itself out, like String, in which case it is shown as a single
+// cell.
+func isStringer(structType reflect.Type) bool {
+	return methodCaster(structType) != nil ||
+		methodCaster(reflect.PtrTo(structType)) != nil
+}
+
+// structFields lists the columns of rowType. Fields of embedded structs are
+// promoted to columns of their own, and nested structs are flattened into
+// "Outer.Inner" columns up to depth levels deep (or further for fields
+// tagged with `tabulate:",flatten"`). Fields tagged with `tabulate:"-"`
+// are left out, and `tabulate:"Name"` renames a column.
+//
+// Columns sharing a name follow Go's rules for promoted fields, like
+// encoding/json does: the shallowest one wins, or the tagged one among
+// several as shallow. Columns that are still ambiguous are left out.
+func structFields(rowType reflect.Type, depth int) []structField {
+	fields := appendStructFields(nil, rowType, "", nil, depth, nil)
+	return dominantFields(fields)
+}
+
+// dominantFields drops the fields shadowed by another of the same name, and
+// those too ambiguous to pick between.
+func dominantFields(fields []structField) []structField {
+	byName := make(map[string][]int)
+	for i, field := range fields {
+		byName[field.name] = append(byName[field.name], i)
+	}
+
+	var dominant []structField
+	for i, field := range fields {
+		if dominantField(fields, byName[field.name]) == i {
+			dominant = append(dominant, field)
+		}
+	}
+	return dominant
+}
+
+// dominantField returns the index of the field that wins among those of the
+// same name at the given indexes, or -1 if none does.
+func dominantField(fields []structField, indexes []int) int {
+	var shallowest []int
+	for _, i := range indexes {
+		switch {
+		case len(shallowest) == 0 ||
+			len(fields[i].path) < len(fields[shallowest[0]].path):
+			shallowest = []int{i}
+		case len(fields[i].path) == len(fields[shallowest[0]].path):
+			shallowest = append(shallowest, i)
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0]
+	}
+
+	winner := -1
+	for _, i := range shallowest {
+		if fields[i].tagged {
+			if winner >= 0 {
+				return -1
+			}
+			winner = i
+		}
+	}
+	return winner
+}
+
+func appendStructFields(fields []structField, rowType reflect.Type,
+	prefix string, path []int, depth int,
+	parents []reflect.Type) []structField {
+
+	for _, seen := range parents {
+		if seen == rowType {
+			// Stop at loops, like struct{ *Self }.
+			return fields
+		}
+	}
+
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		name, flatten := parseTag(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldPath := make([]int, len(path), len(path)+1)
+		copy(fieldPath, path)
+		fieldPath = append(fieldPath, i)
+
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		isStruct := nested.Kind() == reflect.Struct && !isStringer(nested)
+
+		if isStruct && field.Anonymous && name == "" {
+			fields = appendStructFields(
+				fields, nested, prefix, fieldPath, depth,
+				append(parents, rowType),
+			)
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		if isStruct && (depth > 0 || flatten) {
+			fields = appendStructFields(
+				fields, nested, prefix+name+".", fieldPath, depth-1,
+				append(parents, rowType),
+			)
+			continue
+		}
+		fields = append(fields, structField{
+			prefix + name, fieldPath, field.Type, tagged,
+		})
+	}
+	return fields
+}
+
+// fieldAt follows path through the nested structs of row, returning an
+// invalid value if it comes across a nil pointer along the way.
+func fieldAt(row reflect.Value, path []int) reflect.Value {
+	for _, i := range path {
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return reflect.Value{}
+			}
+			row = row.Elem()
+		}
+		row = row.Field(i)
+	}
+	return row
+}
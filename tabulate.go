@@ -48,6 +48,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"golang.org/x/exp/utf8string"
 )
@@ -62,119 +63,951 @@ type Layout struct {
 	Format      TableFormatterInterface
 	HideHeaders bool
 	Headers     []string
+
+	// MissingValue is shown in place of cells that are absent from the
+	// data, such as the end of a short row in a slice of slices, and in
+	// place of nil values (nil rows, nil pointers and nil interfaces).
+	MissingValue string
+	// WidestRow sizes a slice of slices to its widest row instead of to
+	// its headers (or first row when the headers are hidden). Any columns
+	// beyond the provided headers get an empty heading.
+	WidestRow bool
+	// FirstSeenKeys orders the columns of a slice of maps by the first row
+	// each key appears in, rather than alphabetically. Keys first seen in
+	// the same row are still sorted alphabetically.
+	FirstSeenKeys bool
+	// FlattenDepth is how many levels of nested struct fields get
+	// flattened into columns of their own, with dotted headers like
+	// "Address.City". Fields of embedded structs are always promoted to
+	// columns of their own.
+	FlattenDepth int
+	// ColumnFormats holds the formats of columns, keyed by the name of
+	// the column in the data: the struct field name (or dotted path), map
+	// key or, for slices of slices, its header.
+	ColumnFormats map[string]*ColumnFormat
+	// IndexFormats holds the formats of columns by their index, for
+	// columns without a name or an entry in ColumnFormats.
+	IndexFormats map[int]*ColumnFormat
+	// Columns picks which columns to show by name, and in what order,
+	// renaming those given a Header. The names are the ones ColumnFormats
+	// uses, or the Header of a ComputedColumn. All columns are shown, in
+	// the order of the data, if Columns is empty.
+	Columns []Column
+	// Filter keeps only the rows it returns true for. It is handed each row
+	// as found in the data, nil rows included.
+	Filter func(row interface{}) bool
+	// Where keeps only the rows satisfying every one of its conditions.
+	Where []Condition
+	// SortBy sorts the rows by the values of one or more columns, before
+	// they are written out. Rows equal on the first key are sorted by the
+	// second, and so on, and rows equal on every key keep their order.
+	SortBy []SortKey
+	// Index adds a column in front of the others numbering the rows.
+	Index *RowIndex
+	// GroupBy gathers the rows with the same value in the named column
+	// together, in the order each value is first seen, and shows the value
+	// only on the first row of its group, and of each page. A line goes
+	// between groups: the BetweenGroups line of formats implementing
+	// GroupFormatterInterface, otherwise the line below the headers. The
+	// expanded and transposed views show the value in every record.
+	GroupBy string
+	// GroupHeadings shows the value of each group of GroupBy on a heading
+	// row of its own, across the whole table, instead of in a column.
+	GroupHeadings bool
+	// Footers adds rows below the body of the table, such as totals.
+	Footers []Footer
+	// Computed adds columns worked out from each row of the data, for data
+	// passed in row by row.
+	Computed []ComputedColumn
+	// View picks how Tabulate lays out the records, a row for each by
+	// default.
+	View View
+	// ExpandWidth switches TableView to ExpandedView when the table would
+	// be wider than it, if set.
+	ExpandWidth int
+	// ParseNumbers looks for numbers in text columns, such as those read
+	// from a CSV file. Columns holding nothing but numbers are then lined
+	// up on their decimal points, and written out with their
+	// ColumnFormat's Number format if they have one.
+	ParseNumbers bool
+}
+
+// format returns the format of the table, SimpleFormat by default.
+func (l *Layout) format() TableFormatterInterface {
+	if l.Format == nil {
+		return SimpleFormat
+	}
+	return l.Format
+}
+
+// Alignment picks where a cell sits within its column.
+type Alignment int
+
+const (
+	// AlignDefault is the alignment every cell gets unless told otherwise,
+	// which is to the right.
+	AlignDefault Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// ColumnFormat holds the formatting options of a single column.
+type ColumnFormat struct {
+	// Number determines how ints and floats are written out.
+	Number NumberFormat
+	// Time determines how time.Time and time.Duration are written out.
+	Time TimeFormat
+	// Verb writes out every cell with fmt.Sprintf, such as "%x" or "%+v",
+	// which lets values implementing fmt.Formatter handle it themselves.
+	Verb string
+	// Anchor lines up the cells of the column on the first occurrence of
+	// this string, the way float columns line up on their decimal point.
+	// Set it to "." to line up string or Stringer columns holding numbers,
+	// or to something like ":" or " " (before a unit) for other text.
+	Anchor string
+	// KeepText leaves the column alone when Layout.ParseNumbers is set,
+	// for things like zip codes and IDs that only look like numbers.
+	KeepText bool
+	// Formatter writes out every (non-nil) cell of the column in place of
+	// everything above, like rendering IDs as short hashes or money as
+	// currency. Use TypedFormatter to write one for a given type.
+	Formatter func(value interface{}) string
+}
+
+var defaultColumnFormat = &ColumnFormat{}
+
+// columnFormat returns the format of the column with the given name and
+// index, or the default format if it has none.
+func (l *Layout) columnFormat(name string, index int) *ColumnFormat {
+	if format, found := l.ColumnFormats[name]; found && format != nil {
+		return format
+	}
+	if format, found := l.IndexFormats[index]; found && format != nil {
+		return format
+	}
+	return defaultColumnFormat
+}
+
+// Column picks a column by Name for Layout.Columns, showing it under Header
+// if one is given.
+type Column struct {
+	Name   string
+	Header string
+}
+
+// selected reports whether the named column is to be shown.
+func (l *Layout) selected(name string) bool {
+	// The GroupBy column is needed to find the groups, even if not shown.
+	if len(l.Columns) == 0 || name == l.GroupBy {
+		return true
+	}
+	for _, col := range l.Columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectColumns puts the columns picked by Layout.Columns in order, under
+// their new headers.
+func selectColumns(columns table, layout *Layout) (table, error) {
+	if len(layout.Columns) == 0 {
+		return columns, nil
+	}
+
+	var selected table
+	for _, pick := range layout.Columns {
+		var found *column
+		for _, col := range columns {
+			if col.name == pick.Name {
+				found = col
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("No column named %q.", pick.Name)
+		}
+		if pick.Header != "" && !layout.HideHeaders {
+			found.header = pick.Header
+		}
+		selected = append(selected, found)
+	}
+	return selected, nil
+}
+
+// ComputedColumn is a column worked out from each row of the data, rather
+// than read from it, like a total from a price and a quantity. Its values
+// are written out and aligned like those of any other column, and its
+// format is found in ColumnFormats by its Header.
+type ComputedColumn struct {
+	Header string
+	// Position is the index the column ends up at among the others. A
+	// negative Position, or one past the last column, puts it last.
+	Position int
+	// Compute is handed each row as found in the data (a struct, a
+	// pointer to one, a slice or a map). It is not called for nil rows.
+	Compute func(row interface{}) interface{}
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+func fetchComputedColumn(computed ComputedColumn, table reflect.Value,
+	colDepth int, layout *Layout) (*column, error) {
+
+	col := &column{name: computed.Header, header: computed.Header}
+
+	cells := make([]reflect.Value, colDepth)
+	for i := 0; i < colDepth; i++ {
+		if row := rowAt(table, i); row.IsValid() {
+			value := computed.Compute(table.Index(i).Interface())
+			cells[i] = reflect.ValueOf(&value).Elem()
+		}
+	}
+	if err := castColumn(col, -1, interfaceType, cells, layout); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// getCell returns a cellGetter for the named column of row-oriented data.
+func getCell(name string, rowType reflect.Type, fields []structField,
+	layout *Layout) (cellGetter, error) {
+
+	for _, computed := range layout.Computed {
+		if computed.Header == name {
+			compute := computed.Compute
+			return func(table reflect.Value, i int) reflect.Value {
+				if !rowAt(table, i).IsValid() {
+					return reflect.Value{}
+				}
+				value := compute(table.Index(i).Interface())
+				return reflect.ValueOf(&value).Elem()
+			}, nil
+		}
+	}
+
+	switch rowType.Kind() {
+	case reflect.Struct:
+		for _, field := range fields {
+			if field.name == name {
+				path := field.path
+				return func(table reflect.Value, i int) reflect.Value {
+					if row := rowAt(table, i); row.IsValid() {
+						return fieldAt(row, path)
+					}
+					return reflect.Value{}
+				}, nil
+			}
+		}
+
+	case reflect.Map:
+		key := reflect.ValueOf(name).Convert(rowType.Key())
+		return func(table reflect.Value, i int) reflect.Value {
+			if row := rowAt(table, i); row.IsValid() {
+				return row.MapIndex(key)
+			}
+			return reflect.Value{}
+		}, nil
+
+	default:
+		for index, header := range layout.Headers {
+			if header == name {
+				return func(table reflect.Value, i int) reflect.Value {
+					row := rowAt(table, i)
+					if row.IsValid() && index < row.Len() {
+						return row.Index(index)
+					}
+					return reflect.Value{}
+				}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("No column named %q.", name)
+}
+
+// arrangeRows filters the rows of row-oriented data, then sorts them, as
+// the layout says. It also returns where each row is in the data, or nil
+// if the rows were left as they are.
+func arrangeRows(table reflect.Value, rowType reflect.Type,
+	fields []structField, layout *Layout) (reflect.Value, []int, error) {
+
+	var err error
+	var positions []int
+
+	if layout.Filter != nil || len(layout.Where) > 0 {
+		getters := make([]cellGetter, len(layout.Where))
+		for k, condition := range layout.Where {
+			getters[k], err = getCell(condition.Name, rowType, fields, layout)
+			if err != nil {
+				return reflect.Value{}, nil, err
+			}
+		}
+		table, positions, err = filterRows(
+			table, layout.Filter, layout.Where, getters,
+		)
+		if err != nil {
+			return reflect.Value{}, nil, err
+		}
+	}
+
+	if len(layout.SortBy) > 0 {
+		getters := make([]cellGetter, len(layout.SortBy))
+		for k, key := range layout.SortBy {
+			getters[k], err = getCell(key.Name, rowType, fields, layout)
+			if err != nil {
+				return reflect.Value{}, nil, err
+			}
+		}
+		var order []int
+		table, order = sortRows(table, layout.SortBy, getters)
+		positions = reorder(positions, order)
+	}
+
+	if layout.GroupBy != "" {
+		getter, err := getCell(layout.GroupBy, rowType, fields, layout)
+		if err != nil {
+			return reflect.Value{}, nil, err
+		}
+		var order []int
+		table, order = groupRows(table, getter)
+		positions = reorder(positions, order)
+	}
+	return table, positions, nil
+}
+
+// reorder returns the positions of rows, as found in the data, once put in
+// the given order. Nil positions are the rows left as they are.
+func reorder(positions []int, order []int) []int {
+	if positions != nil {
+		for i, from := range order {
+			order[i] = positions[from]
+		}
+	}
+	return order
+}
+
+// RowIndex adds a column in front of the others numbering the rows, or
+// labelling them, like the index of a data frame.
+type RowIndex struct {
+	// Header is the heading of the index column, and its name for
+	// ColumnFormats and Footers.
+	Header string
+	// Start is the number of the first row, usually 0 or 1.
+	Start int
+	// Labels are shown in place of numbers, one for each row of the data.
+	Labels []string
+	// Renumber numbers the rows in the order they are shown. Otherwise,
+	// each row keeps the number of its position in the data, whichever way
+	// SortBy and Filter move it around. Labels always stay with their row.
+	Renumber bool
+}
+
+// indexColumn builds the column of Layout.Index for a table of dataLength
+// rows, rearranged as positions says (nil if left as they are).
+func indexColumn(index *RowIndex, dataLength int, positions []int,
+	layout *Layout) (*column, error) {
+
+	if index.Labels != nil && len(index.Labels) != dataLength {
+		return nil, fmt.Errorf(
+			"Index has %d labels, but the data has %d rows.",
+			len(index.Labels), dataLength,
+		)
+	}
+	if positions == nil {
+		positions = make([]int, dataLength)
+		for i := range positions {
+			positions[i] = i
+		}
+	}
+
+	col := &column{name: index.Header}
+	if !layout.HideHeaders {
+		col.header = index.Header
+	}
+
+	var cells reflect.Value
+	if index.Labels != nil {
+		cells = reflect.ValueOf(make([]string, len(positions)))
+		for i, from := range positions {
+			cells.Index(i).SetString(index.Labels[from])
+		}
+	} else {
+		cells = reflect.ValueOf(make([]int, len(positions)))
+		for i, from := range positions {
+			if index.Renumber {
+				from = i
+			}
+			cells.Index(i).SetInt(int64(index.Start + from))
+		}
+	}
+
+	values := make([]reflect.Value, cells.Len())
+	for i := range values {
+		values[i] = cells.Index(i)
+	}
+	err := castColumn(col, -1, cells.Type().Elem(), values, layout)
+	if err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// addIndex puts the column of Layout.Index, if any, in front of columns.
+func addIndex(columns table, dataLength int, positions []int,
+	layout *Layout) (table, error) {
+
+	if layout.Index == nil {
+		return columns, nil
+	}
+	col, err := indexColumn(layout.Index, dataLength, positions, layout)
+	if err != nil {
+		return nil, err
+	}
+	return append(table{col}, columns...), nil
+}
+
+// NamedColumn holds the values of a single column, for data that is
+// gathered column by column rather than row by row. Values must be a slice
+// or an array.
+type NamedColumn struct {
+	Header string
+	Values interface{}
 }
 
 func getRowType(table interface{}) (reflect.Type, error) {
 	tableType := reflect.TypeOf(table)
-	if reflect.Slice == tableType.Kind() {
+	if tableType != nil && (reflect.Slice == tableType.Kind() ||
+		reflect.Array == tableType.Kind()) {
 		rowType := tableType.Elem()
 		if reflect.Ptr == rowType.Kind() {
 			rowType = rowType.Elem()
 		}
 
 		switch rowType.Kind() {
-		case reflect.Struct, reflect.Slice:
+		case reflect.Struct, reflect.Slice, reflect.Array:
 			return rowType, nil
+		case reflect.Map:
+			if rowType.Key().Kind() == reflect.String {
+				return rowType, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf(
+		"Must pass in a slice or an array of structs, slices or maps " +
+			"(or of pointers to them).",
+	)
+}
+
+// collectRows drains a lazy source of rows into a slice, so it can be sized
+// like any other table. Sources are iterators (an iter.Seq, or an iter.Seq2
+// whose keys are ints) and channels, which are consumed until closed. It
+// returns false if data is not a lazy source.
+func collectRows(data reflect.Value) (reflect.Value, bool) {
+	dataType := data.Type()
+
+	switch dataType.Kind() {
+	case reflect.Chan:
+		if dataType.ChanDir()&reflect.RecvDir == 0 {
+			return reflect.Value{}, false
+		}
+		rows := reflect.MakeSlice(reflect.SliceOf(dataType.Elem()), 0, 0)
+		if data.IsNil() {
+			// Receiving from it would block forever
+			return rows, true
+		}
+		for {
+			row, ok := data.Recv()
+			if !ok {
+				return rows, true
+			}
+			rows = reflect.Append(rows, row)
+		}
+
+	case reflect.Func:
+		if dataType.NumIn() != 1 || dataType.NumOut() != 0 {
+			return reflect.Value{}, false
+		}
+		yieldType := dataType.In(0)
+		if yieldType.Kind() != reflect.Func || yieldType.NumOut() != 1 ||
+			yieldType.Out(0).Kind() != reflect.Bool {
+			return reflect.Value{}, false
+		}
+
+		var rowType reflect.Type
+		switch yieldType.NumIn() {
+		case 1:
+			rowType = yieldType.In(0)
+		case 2:
+			if yieldType.In(0).Kind() != reflect.Int {
+				return reflect.Value{}, false
+			}
+			rowType = yieldType.In(1)
+		default:
+			return reflect.Value{}, false
+		}
+
+		rows := reflect.MakeSlice(reflect.SliceOf(rowType), 0, 0)
+		if data.IsNil() {
+			return rows, true
 		}
+		yield := reflect.MakeFunc(yieldType,
+			func(args []reflect.Value) []reflect.Value {
+				rows = reflect.Append(rows, args[len(args)-1])
+				return []reflect.Value{reflect.ValueOf(true)}
+			},
+		)
+		data.Call([]reflect.Value{yield})
+		return rows, true
 	}
-	return nil, fmt.Errorf("Must pass in slice of struct pointers.")
+	return reflect.Value{}, false
 }
 
+// rowAt returns row i of table, following the pointer to it if need be.
+// The returned row is invalid when that pointer is nil.
+func rowAt(table reflect.Value, i int) reflect.Value {
+	row := table.Index(i)
+	if row.Kind() == reflect.Ptr {
+		if row.IsNil() {
+			return reflect.Value{}
+		}
+		return row.Elem()
+	}
+	return row
+}
+
+// column is a column of the table. Its name is what the column is known
+// by in the data (the struct field name, map key or header), for finding
+// its formats, while its header is what gets drawn.
 type column struct {
+	name   string
 	header string
 	column []string
+	// aligns holds the alignment of each cell, if any of them has one.
+	aligns []Alignment
+	// footer holds the cell of each footer row.
+	footer []string
+	// groups holds the rows starting a group of Layout.GroupBy, with the
+	// heading of the group, if any. It is the same for every column.
+	groups map[int]string
+	// groupKey is whether this is the GroupBy column, whose values are
+	// only shown on the first row of each group (and of each page).
+	groupKey bool
+	// spans holds how many columns each Span in the column covers, by row.
+	spans map[int]int
+	// width is the width the column is drawn at, once aligned.
+	width int
 }
 
-func fetchStructColumn(rowType reflect.Type, table reflect.Value, colDepth int,
-	customHeaders []string, index int) (*column, error) {
-	var col = &column{}
+// castColumn casts every cell into the text of col. Invalid cells, which
+// are absent from the data, are shown as Layout.MissingValue.
+func castColumn(col *column, index int, cellType reflect.Type,
+	cells []reflect.Value, layout *Layout) error {
 
-	header := rowType.Field(index)
-	if customHeaders == nil {
-		col.header = header.Name
-	} else {
-		col.header = customHeaders[index]
-	}
-	caster, err := guessCaster(header.Type)
+	format := layout.columnFormat(col.name, index)
+	caster, err := guessCaster(cellType, format, layout.MissingValue)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	for i, cell := range cells {
+		if !cell.IsValid() {
+			col.column = append(col.column, layout.MissingValue)
+			continue
+		}
+		if span, ok := spanOf(cell); ok {
+			text, err := castValue(span.Value, format, layout.MissingValue)
+			if err != nil {
+				return err
+			}
+			col.column = append(col.column, text)
+			if col.spans == nil {
+				col.spans = make(map[int]int)
+			}
+			col.spans[i] = span.Columns
+			if col.aligns == nil {
+				col.aligns = make([]Alignment, len(cells))
+			}
+			col.aligns[i] = span.Align
+			continue
+		}
+		if format.Verb != "" || format.Formatter != nil {
+			col.column = append(col.column, caster(cell))
+			continue
+		}
+		if text, align, ok := cellText(cell); ok {
+			col.column = append(col.column, text)
+			if col.aligns == nil {
+				col.aligns = make([]Alignment, len(cells))
+			}
+			col.aligns[i] = align
+			continue
+		}
+		col.column = append(col.column, caster(cell))
+	}
+	anchor := format.Anchor
+	if anchor == "" && (isFloat(cellType) || isMixedNumeric(cellType, cells)) {
+		anchor = "."
+	}
+	if layout.ParseNumbers && !format.KeepText && isText(cellType) {
+		numeric, floats := parseNumbers(
+			col.column, &format.Number, layout.MissingValue,
+		)
+		if numeric && floats && anchor == "" {
+			anchor = "."
+		}
+	}
+	if len(layout.Footers) > 0 {
+		col.footer, err = footerCells(
+			layout.Footers, col.name, cells, format, layout.MissingValue,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if anchor != "" {
+		// Footers are lined up with the body, so totals sit under it.
+		body := len(col.column)
+		all := append(col.column[:body:body], col.footer...)
+		spanTexts := make(map[int]string)
+		for i := range col.spans {
+			spanTexts[i] = all[i]
+		}
+		alignOn(all, anchor)
+		// Spans are aligned across their columns instead
+		for i, text := range spanTexts {
+			all[i] = text
+		}
+		copy(col.footer, all[body:])
+		col.column = all[:body]
+	}
+	return nil
+}
+
+func fetchStructColumn(field structField, table reflect.Value, colDepth int,
+	layout *Layout, index int) (*column, error) {
+	var col = &column{name: field.name}
+
+	if layout.Headers == nil {
+		col.header = field.name
+	} else {
+		col.header = layout.Headers[index]
+	}
+
+	cells := make([]reflect.Value, colDepth)
 	for i := 0; i < colDepth; i++ {
-		row := table.Index(i).Elem()
-		col.column = append(col.column, caster(row.Field(index)))
+		if row := rowAt(table, i); row.IsValid() {
+			cells[i] = fieldAt(row, field.path)
+		}
 	}
-	if header.Type.Kind() == reflect.Float32 ||
-		header.Type.Kind() == reflect.Float64 {
-		alignFloats(col.column)
+	err := castColumn(col, index, field.cellType, cells, layout)
+	if err != nil {
+		return nil, err
 	}
 	return col, nil
 }
 
 func fetchMatrixColumn(rowType reflect.Type, table reflect.Value, colDepth int,
-	customHeaders []string, hideHeaders bool, index int) (*column, error) {
+	layout *Layout, index int) (*column, error) {
 
 	col := &column{}
 
-	if !hideHeaders {
-		col.header = customHeaders[index]
+	if index < len(layout.Headers) {
+		col.name = layout.Headers[index]
+	}
+	if !layout.HideHeaders {
+		col.header = col.name
 	}
-	cellType := rowType.Elem()
-	caster, err := guessCaster(cellType)
 
-	if err != nil {
+	cells := make([]reflect.Value, colDepth)
+	for i := 0; i < colDepth; i++ {
+		row := rowAt(table, i)
+		if row.IsValid() && index < row.Len() {
+			cells[i] = row.Index(index)
+		}
+	}
+	if err := castColumn(col, index, rowType.Elem(), cells, layout); err != nil {
 		return nil, err
 	}
+	return col, nil
+}
 
+func fetchMapColumn(rowType reflect.Type, table reflect.Value, colDepth int,
+	key string, layout *Layout, index int) (*column, error) {
+
+	col := &column{name: key}
+
+	if !layout.HideHeaders {
+		col.header = key
+	}
+
+	keyV := reflect.ValueOf(key).Convert(rowType.Key())
+	cells := make([]reflect.Value, colDepth)
 	for i := 0; i < colDepth; i++ {
-		cell := table.Index(i).Index(index)
-		col.column = append(col.column, caster(cell))
+		if row := rowAt(table, i); row.IsValid() {
+			cells[i] = row.MapIndex(keyV)
+		}
 	}
-	if cellType.Kind() == reflect.Float32 || cellType.Kind() == reflect.Float64 {
-		alignFloats(col.column)
+	if err := castColumn(col, index, rowType.Elem(), cells, layout); err != nil {
+		return nil, err
 	}
 	return col, nil
 }
 
+// mapKeys returns the union of the keys of every map in table, either
+// sorted or in the order they are first seen.
+func mapKeys(table reflect.Value, firstSeen bool) []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for i := 0; i < table.Len(); i++ {
+		var rowKeys []string
+		row := rowAt(table, i)
+		if !row.IsValid() {
+			continue
+		}
+		for _, key := range row.MapKeys() {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				rowKeys = append(rowKeys, key.String())
+			}
+		}
+		sort.Strings(rowKeys)
+		keys = append(keys, rowKeys...)
+	}
+
+	if !firstSeen {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+func fetchColumnValues(name string, header string, values reflect.Value,
+	layout *Layout, index int) (*column, error) {
+
+	col := &column{name: name, header: header}
+
+	if values.Kind() == reflect.Interface {
+		values = values.Elem()
+	}
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Column values must be a slice or an array.")
+	}
+
+	cells := make([]reflect.Value, values.Len())
+	for i := range cells {
+		cells[i] = values.Index(i)
+	}
+	err := castColumn(col, index, values.Type().Elem(), cells, layout)
+	if err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// buildColumnTable builds a table out of column-oriented data, either a
+// []NamedColumn or a map of headers to column values.
+func buildColumnTable(data reflect.Value, layout *Layout) (table, error) {
+	var columns table
+
+	if len(layout.Computed) > 0 || len(layout.SortBy) > 0 ||
+		layout.Filter != nil || len(layout.Where) > 0 ||
+		layout.GroupBy != "" {
+		return nil, fmt.Errorf(
+			"Computed columns, sorting, filtering and grouping need data " +
+				"passed in row by row.",
+		)
+	}
+
+	if named, ok := data.Interface().([]NamedColumn); ok {
+		if layout.Headers != nil && len(layout.Headers) != len(named) {
+			return nil, fmt.Errorf(
+				"Layout has %d headers, but the data has %d columns.",
+				len(layout.Headers), len(named),
+			)
+		}
+		for i, namedCol := range named {
+			if !layout.selected(namedCol.Header) {
+				continue
+			}
+			header := namedCol.Header
+			if layout.Headers != nil {
+				header = layout.Headers[i]
+			}
+			col, err := fetchColumnValues(
+				namedCol.Header, header, reflect.ValueOf(namedCol.Values),
+				layout, i,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("Error with col %d: %s", i, err)
+			}
+			columns = append(columns, col)
+		}
+	} else {
+		headers := layout.Headers
+		if headers == nil {
+			for _, key := range data.MapKeys() {
+				headers = append(headers, key.String())
+			}
+			sort.Strings(headers)
+		}
+		for i, header := range headers {
+			if !layout.selected(header) {
+				continue
+			}
+			values := data.MapIndex(
+				reflect.ValueOf(header).Convert(data.Type().Key()),
+			)
+			if !values.IsValid() {
+				return nil, fmt.Errorf("No column named %q.", header)
+			}
+			col, err := fetchColumnValues(header, header, values, layout, i)
+			if err != nil {
+				return nil, fmt.Errorf("Error with col %d: %s", i, err)
+			}
+			columns = append(columns, col)
+		}
+	}
+
+	for _, col := range columns {
+		if len(col.column) != len(columns[0].column) {
+			return nil, fmt.Errorf(
+				"Column %q has %d values, but column %q has %d.",
+				col.header, len(col.column),
+				columns[0].header, len(columns[0].column),
+			)
+		}
+	}
+
+	columns, err := selectColumns(columns, layout)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("Data must have at least one column.")
+	}
+	return addIndex(columns, len(columns[0].column), nil, layout)
+}
+
 type table []*column
 
 func (t table) columnWidths(countHeaders bool) []int {
-	var colWidths []int
+	colWidths := make([]int, len(t))
 
-	for _, col := range t {
-		colLength := 0
+	for colI, col := range t {
 		if countHeaders {
-			colLength = len(col.header)
+			colWidths[colI] = utf8Len(col.header)
 		}
+		for _, cell := range col.footer {
+			if utf8Len(cell) > colWidths[colI] {
+				colWidths[colI] = utf8Len(cell)
+			}
+		}
+	}
 
-		for _, cell := range col.column {
-			if len(cell) > colLength {
-				colLength = len(cell)
+	// Spans are sized by prepare, once the spacing of the format is known,
+	// and the cells they cover are not shown
+	for row := 0; len(t) > 0 && row < len(t[0].column); row++ {
+		for colI, span := range t.rowSpans(row) {
+			cell := t[colI].column[row]
+			if span == 1 && t[colI].spans[row] < 2 &&
+				utf8Len(cell) > colWidths[colI] {
+				colWidths[colI] = utf8Len(cell)
 			}
 		}
-		colWidths = append(colWidths, colLength)
 	}
 	return colWidths
 }
 
+// alignText pads text out to width, as align says.
+func alignText(text string, align Alignment, width int) string {
+	switch align {
+	case AlignLeft:
+		return leftAlign(text, ' ', width)
+	case AlignCenter:
+		return center(text, ' ', width)
+	}
+	return fmt.Sprintf("%[1]*[2]s", width, text)
+}
+
 func (t table) align(widths []int, showHeaders bool) {
 	for colI, col := range t {
+		col.width = widths[colI]
 		if showHeaders {
 			col.header = fmt.Sprintf("%[1]*[2]s", widths[colI], col.header)
 		}
 		for i := 0; i < len(col.column); i++ {
-			col.column[i] = fmt.Sprintf("%[1]*[2]s", widths[colI], col.column[i])
+			if col.spans[i] > 1 {
+				// Aligned across its columns when drawn
+				continue
+			}
+			align := AlignDefault
+			if i < len(col.aligns) {
+				align = col.aligns[i]
+			}
+			col.column[i] = alignText(col.column[i], align, widths[colI])
+		}
+		for i := 0; i < len(col.footer); i++ {
+			col.footer[i] = fmt.Sprintf("%[1]*[2]s", widths[colI], col.footer[i])
 		}
 	}
 }
 
 func (t table) draw(format TableFormatterInterface, showHeaders bool) string {
-	var output []string
+	t.prepare(format, showHeaders)
+	return t.drawRows(format, showHeaders, 0, len(t[0].column), true)
+}
 
-	columnWidths := t.columnWidths(showHeaders)
+// prepare sizes and aligns the cells of the table, and hands the widths to
+// format, before any of it is drawn.
+func (t table) prepare(format TableFormatterInterface, showHeaders bool) {
+	columnWidths := t.fitWidths(format, showHeaders)
 	t.align(columnWidths, showHeaders)
 	format.RegisterWidths(columnWidths)
+}
+
+// fitWidths returns the widths of the columns, made wide enough for the
+// spans and the group headings drawn across them in format.
+func (t table) fitWidths(format TableFormatterInterface, showHeaders bool) []int {
+	columnWidths := t.columnWidths(showHeaders)
+
+	// The last column a span covers makes room for it, if need be
+	for row := 0; row < len(t[0].column); row++ {
+		for colI, span := range t.rowSpans(row) {
+			if t[colI].spans[row] < 2 || span == 0 {
+				continue
+			}
+			covered := columnWidths[colI : colI+span]
+			extra := utf8Len(t[colI].column[row]) - t.spanLength(format, covered)
+			if extra > 0 {
+				covered[span-1] += extra
+			}
+		}
+	}
+	// and the last column of all makes room for group headings
+	for _, heading := range t[0].groups {
+		if extra := utf8Len(heading) - t.spanLength(format, columnWidths); extra > 0 {
+			columnWidths[len(columnWidths)-1] += extra
+		}
+	}
+	return columnWidths
+}
+
+// spanLength is how many characters a cell spanning columns of the given
+// widths takes up.
+func (t table) spanLength(format TableFormatterInterface, widths []int) int {
+	return blockLength(format, widths) -
+		utf8Len(format.LinePrefix()) - utf8Len(format.LinePostfix())
+}
+
+// drawRows draws the rows from start up to end of a prepared table, along
+// with the headers and the footers, if asked for.
+func (t table) drawRows(format TableFormatterInterface, showHeaders bool,
+	start int, end int, footers bool) string {
+
+	var output []string
 
 	appendRow := func(rows []string, row string) []string {
 		if len(row) > 0 {
@@ -189,6 +1022,45 @@ func (t table) draw(format TableFormatterInterface, showHeaders bool) string {
 			format.LinePostfix()
 	}
 
+	widths := make([]int, len(t))
+	for i, col := range t {
+		widths[i] = col.width
+	}
+	groups := t[0].groups
+	// headingAt returns the heading drawn above a row, which a page
+	// starting in the middle of a group repeats.
+	headingAt := func(rowI int) string {
+		if rowI == start {
+			return t.groupHeading(rowI)
+		}
+		return groups[rowI]
+	}
+
+	// Lines across the table only join up with the lines between columns
+	// that the rows above and below them have.
+	spanFormat, joins := format.(SpanFormatterInterface)
+	junctions := func(above []bool, below []bool) {
+		if joins {
+			spanFormat.Junctions(above, below)
+		}
+	}
+	allSplit := t.splits(-1)
+	noSplits := make([]bool, len(allSplit))
+	firstSplits := func(rowI int) []bool {
+		if rowI >= end {
+			return allSplit
+		}
+		if headingAt(rowI) != "" {
+			return noSplits
+		}
+		return t.splits(rowI)
+	}
+
+	last := allSplit
+	if !showHeaders {
+		last = firstSplits(start)
+	}
+	junctions(last, last)
 	output = appendRow(output, format.AboveTable())
 	if showHeaders {
 		parts := make([]string, len(t))
@@ -196,48 +1068,128 @@ func (t table) draw(format TableFormatterInterface, showHeaders bool) string {
 			parts[i] = col.header
 		}
 		output = append(output, joinTokens(parts))
+		junctions(last, firstSplits(start))
 		output = appendRow(output, format.BelowHeader())
 	}
 
-	for rowI := 0; rowI < len(t[0].column); rowI++ {
-		parts := make([]string, len(t))
-		for i, col := range t {
-			parts[i] = col.column[rowI]
+	for rowI := start; rowI < end; rowI++ {
+		if heading := headingAt(rowI); heading != "" {
+			output = append(output, format.LinePrefix()+
+				leftAlign(heading, ' ', t.spanLength(format, widths))+
+				format.LinePostfix())
+			junctions(noSplits, t.splits(rowI))
+			output = appendRow(output, format.BetweenRow(rowI))
+		}
+
+		_, groupStart := groups[rowI]
+		var parts []string
+		for i, span := range t.rowSpans(rowI) {
+			cell := t[i].column[rowI]
+			if span == 0 {
+				continue
+			}
+			if t[i].groupKey && !groupStart && rowI != start {
+				cell = strings.Repeat(" ", t[i].width)
+			} else if t[i].spans[rowI] > 1 {
+				align := AlignDefault
+				if rowI < len(t[i].aligns) {
+					align = t[i].aligns[rowI]
+				}
+				cell = alignText(
+					cell, align, t.spanLength(format, widths[i:i+span]),
+				)
+			}
+			parts = append(parts, cell)
 		}
 		output = appendRow(output, joinTokens(parts))
+		last = t.splits(rowI)
 
-		if rowI < len(t[0].column)-1 {
-			output = appendRow(output, format.BetweenRow(rowI))
+		if rowI < end-1 {
+			junctions(last, firstSplits(rowI+1))
+			if _, found := groups[rowI+1]; !found {
+				output = appendRow(output, format.BetweenRow(rowI))
+			} else if bar, always := groupSeparator(format, rowI); always {
+				output = append(output, bar)
+			} else {
+				output = appendRow(output, bar)
+			}
 		}
 	}
+
+	if footers && len(t[0].footer) > 0 {
+		junctions(last, allSplit)
+		output = appendRow(output, format.AboveFooter())
+		for rowI := 0; rowI < len(t[0].footer); rowI++ {
+			parts := make([]string, len(t))
+			for i, col := range t {
+				parts[i] = col.footer[rowI]
+			}
+			output = appendRow(output, joinTokens(parts))
+		}
+		last = allSplit
+	}
+	junctions(last, last)
 	output = appendRow(output, format.BelowTable())
 
 	return strings.Join(output, "\n") + "\n"
 }
 
 func buildTable(data interface{}, layout *Layout) (table, error) {
+	if data != nil {
+		if rows, ok := collectRows(reflect.ValueOf(data)); ok {
+			data = rows.Interface()
+		}
+	}
+	if _, ok := data.([]NamedColumn); ok {
+		return buildColumnTable(reflect.ValueOf(data), layout)
+	}
+	if dataType := reflect.TypeOf(data); dataType != nil &&
+		dataType.Kind() == reflect.Map &&
+		dataType.Key().Kind() == reflect.String {
+		return buildColumnTable(reflect.ValueOf(data), layout)
+	}
+
 	rowType, err := getRowType(data)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	var fields []structField
+	if rowType.Kind() == reflect.Struct {
+		fields = structFields(rowType, layout.FlattenDepth)
 	}
 
-	tableV := reflect.ValueOf(data)
+	tableV, positions, err := arrangeRows(
+		reflect.ValueOf(data), rowType, fields, layout,
+	)
+	if err != nil {
+		return nil, err
+	}
 	tableLength := tableV.Len()
 
 	var columns table
-	var isStruct bool
 	var colCount int
+	var keys []string
 
 	switch rowType.Kind() {
 	case reflect.Struct:
-		isStruct = true
-		colCount = rowType.NumField()
+		colCount = len(fields)
 
-	case reflect.Slice:
-		isStruct = false
+	case reflect.Map:
+		keys = layout.Headers
+		if keys == nil {
+			keys = mapKeys(tableV, layout.FirstSeenKeys)
+		}
+		colCount = len(keys)
+
+	case reflect.Slice, reflect.Array:
 		if layout.HideHeaders {
 			// Take the length of the first row as the tables width
-			colCount = tableV.Index(0).Len()
+			if tableLength > 0 {
+				if row := rowAt(tableV, 0); row.IsValid() {
+					colCount = row.Len()
+				}
+			}
 		} else {
 			if layout.Headers == nil {
 				return nil, fmt.Errorf(
@@ -246,10 +1198,18 @@ func buildTable(data interface{}, layout *Layout) (table, error) {
 			}
 			colCount = len(layout.Headers)
 		}
+		if layout.WidestRow {
+			for i := 0; i < tableLength; i++ {
+				row := rowAt(tableV, i)
+				if row.IsValid() && row.Len() > colCount {
+					colCount = row.Len()
+				}
+			}
+		}
 
 	default:
 		return nil, fmt.Errorf(
-			"Inputted data must be a slice of slices or a slice of structs.",
+			"Inputted data must be a slice of slices, maps or structs.",
 		)
 	}
 
@@ -257,14 +1217,24 @@ func buildTable(data interface{}, layout *Layout) (table, error) {
 		var rows *column
 		var err error
 
-		if isStruct {
+		switch rowType.Kind() {
+		case reflect.Struct:
+			if !layout.selected(fields[col].name) {
+				continue
+			}
 			rows, err = fetchStructColumn(
-				rowType, tableV, tableLength, layout.Headers, col,
+				fields[col], tableV, tableLength, layout, col,
 			)
-		} else {
+		case reflect.Map:
+			if !layout.selected(keys[col]) {
+				continue
+			}
+			rows, err = fetchMapColumn(
+				rowType, tableV, tableLength, keys[col], layout, col,
+			)
+		default:
 			rows, err = fetchMatrixColumn(
-				rowType, tableV, tableLength, layout.Headers,
-				layout.HideHeaders, col,
+				rowType, tableV, tableLength, layout, col,
 			)
 		}
 
@@ -274,6 +1244,58 @@ func buildTable(data interface{}, layout *Layout) (table, error) {
 		columns = append(columns, rows)
 	}
 
+	computed := make([]ComputedColumn, len(layout.Computed))
+	copy(computed, layout.Computed)
+	sort.SliceStable(computed, func(i, j int) bool {
+		return computed[j].Position < 0 ||
+			computed[i].Position >= 0 &&
+				computed[i].Position < computed[j].Position
+	})
+	for _, comp := range computed {
+		if !layout.selected(comp.Header) {
+			continue
+		}
+		col, err := fetchComputedColumn(comp, tableV, tableLength, layout)
+		if err != nil {
+			return nil, fmt.Errorf("Error with col %q: %s", comp.Header, err)
+		}
+
+		position := comp.Position
+		if position < 0 || position > len(columns) {
+			position = len(columns)
+		}
+		columns = append(columns, nil)
+		copy(columns[position+1:], columns[position:])
+		columns[position] = col
+	}
+
+	var starts []int
+	if layout.GroupBy != "" {
+		getter, err := getCell(layout.GroupBy, rowType, fields, layout)
+		if err != nil {
+			return nil, err
+		}
+		starts = groupStarts(tableV, getter)
+	}
+	groups, err := groupColumns(columns, layout, starts)
+	if err != nil {
+		return nil, err
+	}
+	columns, err = selectColumns(columns, layout)
+	if err != nil {
+		return nil, err
+	}
+	// The index counts the rows filtered out too.
+	columns, err = addIndex(columns, reflect.ValueOf(data).Len(), positions, layout)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("Data must have at least one column.")
+	}
+	for _, col := range columns {
+		col.groups = groups
+	}
 	return columns, nil
 }
 
@@ -283,9 +1305,21 @@ func buildTable(data interface{}, layout *Layout) (table, error) {
 // Data
 //
 // The data parameter must either be a slice of structs, and the table will
-// use the field names of the struct as column names. If provided a slice
-// of slices of strings, you will need to provide a list of Headers (mostly
-// so it can figure out how many columns to size for).
+// use the field names of the struct as column names. Struct fields can be
+// renamed with a `tabulate:"Name"` tag, or left out with `tabulate:"-"`.
+// If provided a slice of slices of strings, you will need to provide a list
+// of Headers (mostly so it can figure out how many columns to size for).
+// If provided a slice of maps keyed by strings, the Headers pick which keys
+// to show; without them every key found in the maps is shown. The rows can
+// be passed in as values or as pointers, in either a slice or an array, and
+// nil pointers are shown as a row of MissingValue. Rows can also come from an iter.Seq, an
+// iter.Seq2 keyed by ints, or a channel. Those are consumed once, and their
+// rows are held onto until the table is drawn, since every row is needed to
+// size the columns.
+//
+// Data can also be passed in column by column, either as a []NamedColumn or
+// as a map of headers to slices of values (like map[string][]float64). Every
+// column must hold the same number of values.
 //
 func Tabulate(data interface{}, layout *Layout) (string, error) {
 	columns, err := buildTable(data, layout)
@@ -293,12 +1327,21 @@ func Tabulate(data interface{}, layout *Layout) (string, error) {
 		return "", err
 	}
 
-	format := layout.Format
-	if format == nil {
-		format = SimpleFormat
+	view, err := layout.view(columns)
+	if err != nil {
+		return "", err
 	}
-
-	return columns.draw(format, !layout.HideHeaders), nil
+	switch view {
+	case ExpandedView:
+		return columns.drawExpanded(), nil
+	case TransposedView:
+		return columns.transpose().draw(layout.format(), false), nil
+	}
+	columns, err = layout.tableView(columns)
+	if err != nil {
+		return "", err
+	}
+	return columns.draw(layout.format(), !layout.HideHeaders), nil
 }
 
 func writePadding(combined *bytes.Buffer, length int, padding string) {
@@ -1,9 +1,16 @@
 package tabulate
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"iter"
+	"math"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 type MyStruct struct {
@@ -465,3 +472,1360 @@ func TestPlacementCombo(t *testing.T) {
 `
 	assert.Equal(t, expecting, combined)
 }
+
+func TestTabulateRaggedRows(t *testing.T) {
+	records := [][]string{
+		[]string{"here", "there", "everywhere"},
+		[]string{"1"},
+	}
+
+	layout := &Layout{
+		Format:       SimpleFormat,
+		Headers:      []string{"a", "b"},
+		MissingValue: "-",
+	}
+
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"   a" + "     b\n" + // 4 + 6
+		"----" + " -----\n" +
+		"here" + " there\n" +
+		"   1" + "     -\n")
+	assert.Equal(t, expecting, table)
+
+	layout.WidestRow = true
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting = ("" +
+		"   a" + "     b" + "           \n" + // 4 + 6 + 11
+		"----" + " -----" + " ----------\n" +
+		"here" + " there" + " everywhere\n" +
+		"   1" + "     -" + "          -\n")
+	assert.Equal(t, expecting, table)
+}
+
+type MyPointerStruct struct {
+	Name   *FullName
+	Amount *int
+	Note   *string
+}
+
+func TestTabulateNilValues(t *testing.T) {
+	amount := 15
+	note := "fresh"
+	records := []*MyPointerStruct{
+		&MyPointerStruct{&FullName{"Roy", "Smith"}, &amount, &note},
+		&MyPointerStruct{nil, nil, nil},
+		nil,
+	}
+
+	layout := &Layout{Format: SimpleFormat, MissingValue: "?"}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"     Name" + " Amount" + "  Note\n" + // 9 + 7 + 6
+		"---------" + " ------" + " -----\n" +
+		"Roy Smith" + "     15" + " fresh\n" +
+		"        ?" + "      ?" + "     ?\n" +
+		"        ?" + "      ?" + "     ?\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateMaps(t *testing.T) {
+	records := []map[string]interface{}{
+		map[string]interface{}{"name": "Apple", "amount": 15},
+		map[string]interface{}{"name": "Orange", "origin": "Spain"},
+	}
+
+	table, err := Tabulate(records, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"amount" + "   name" + " origin\n" + // 6 + 7 + 7
+		"------" + " ------" + " ------\n" +
+		"    15" + "  Apple" + "       \n" +
+		"      " + " Orange" + "  Spain\n")
+	assert.Equal(t, expecting, table)
+
+	layout := &Layout{Format: SimpleFormat, Headers: []string{"name", "origin"}}
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting = ("" +
+		"  name" + " origin\n" + // 6 + 7
+		"------" + " ------\n" +
+		" Apple" + "       \n" +
+		"Orange" + "  Spain\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateMapsFirstSeen(t *testing.T) {
+	records := []map[string]int{
+		map[string]int{"b": 1, "c": 2},
+		map[string]int{"a": 3, "b": 4},
+	}
+
+	layout := &Layout{Format: SimpleFormat, FirstSeenKeys: true}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"b" + " c" + " a\n" +
+		"-" + " -" + " -\n" +
+		"1" + " 2" + "  \n" +
+		"4" + "  " + " 3\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateColumns(t *testing.T) {
+	expecting := ("" +
+		"  name" + " amount\n" + // 6 + 7
+		"------" + " ------\n" +
+		" Apple" + "     15\n" +
+		"Orange" + "      1\n")
+
+	named := []NamedColumn{
+		NamedColumn{"name", []string{"Apple", "Orange"}},
+		NamedColumn{"amount", []int{15, 1}},
+	}
+	table, err := Tabulate(named, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	columns := map[string]interface{}{
+		"name":   []string{"Apple", "Orange"},
+		"amount": []int{15, 1},
+	}
+	layout := &Layout{Format: SimpleFormat, Headers: []string{"name", "amount"}}
+	table, err = Tabulate(columns, layout)
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	floats := map[string][]float64{
+		"b": []float64{1.5, 10},
+		"a": []float64{2, 0.25},
+	}
+	table, err = Tabulate(floats, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"   a" + "    b\n" + // 4 + 5
+		"----" + " ----\n" +
+		"2   " + "  1.5\n" +
+		"0.25" + " 10  \n"), table)
+}
+
+func TestTabulateColumnsMismatch(t *testing.T) {
+	named := []NamedColumn{
+		NamedColumn{"name", []string{"Apple", "Orange"}},
+		NamedColumn{"amount", []int{15}},
+	}
+	_, err := Tabulate(named, &Layout{Format: SimpleFormat})
+	assert.EqualError(t, err, `Column "amount" has 1 values, but column "name" has 2.`)
+
+	_, err = Tabulate(named, &Layout{Headers: []string{"name"}})
+	assert.EqualError(t, err, "Layout has 1 headers, but the data has 2 columns.")
+
+	_, err = Tabulate([]NamedColumn{}, &Layout{})
+	assert.EqualError(t, err, "Data must have at least one column.")
+	_, err = Tabulate(map[string][]int{}, &Layout{})
+	assert.EqualError(t, err, "Data must have at least one column.")
+}
+
+func TestTabulateMixedCells(t *testing.T) {
+	records := [][]interface{}{
+		[]interface{}{"disk", 42, 0.93, true},
+		[]interface{}{&FullName{"Roy", "Smith"}, nil, 12, false},
+	}
+
+	layout := &Layout{
+		Format:       SimpleFormat,
+		Headers:      []string{"what", "count", "ratio", "ok"},
+		MissingValue: "-",
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"     what" + " count" + " ratio" + "    ok\n" + // 9 + 6 + 6 + 6
+		"---------" + " -----" + " -----" + " -----\n" +
+		"     disk" + "    42" + "  0.93" + "  true\n" +
+		"Roy Smith" + "     -" + " 12   " + " false\n")
+	assert.Equal(t, expecting, table)
+}
+
+type MyInterfaceStruct struct {
+	Name  string
+	Value interface{}
+}
+
+func TestTabulateInterfaceField(t *testing.T) {
+	records := []*MyInterfaceStruct{
+		&MyInterfaceStruct{"size", 1.5},
+		&MyInterfaceStruct{"label", "big"},
+	}
+
+	table, err := Tabulate(records, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+
+	expecting := ("" +
+		" Name" + " Value\n" + // 5 + 6
+		"-----" + " -----\n" +
+		" size" + "   1.5\n" +
+		"label" + "   big\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateRowContainers(t *testing.T) {
+	expecting := ("" +
+		"  name" + " amount\n" + // 6 + 7
+		"------" + " ------\n" +
+		" Apple" + "     15\n" +
+		"Orange" + "      1\n")
+
+	values := []MyStruct{MyStruct{"Apple", 15}, MyStruct{"Orange", 1}}
+	table, err := Tabulate(values, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	array := [2]MyStruct{MyStruct{"Apple", 15}, MyStruct{"Orange", 1}}
+	table, err = Tabulate(array, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	pointers := [2]*MyStruct{testData[0], testData[1]}
+	table, err = Tabulate(pointers, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	matrix := [][2]string{[2]string{"Apple", "15"}, [2]string{"Orange", "1"}}
+	layout := &Layout{Format: SimpleFormat, Headers: []string{"name", "amount"}}
+	table, err = Tabulate(matrix, layout)
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateBadContainers(t *testing.T) {
+	for _, data := range []interface{}{nil, "Apple", []int{1, 2}, testData[0]} {
+		_, err := Tabulate(data, &Layout{})
+		assert.NotNil(t, err, "Expecting an error for %#v", data)
+	}
+
+	// Nothing to size the columns by
+	_, err := Tabulate([][]string{}, &Layout{HideHeaders: true})
+	assert.EqualError(t, err, "Data must have at least one column.")
+	_, err = Paginate([]map[string]int{}, &Layout{}, Paging{Rows: 1})
+	assert.EqualError(t, err, "Data must have at least one column.")
+}
+
+func TestTabulateLazyRows(t *testing.T) {
+	expecting := ("" +
+		"  name" + " amount\n" + // 6 + 7
+		"------" + " ------\n" +
+		" Apple" + "     15\n" +
+		"Orange" + "      1\n")
+
+	var seq iter.Seq[*MyStruct] = slices.Values(testData)
+	table, err := Tabulate(seq, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	var seq2 iter.Seq2[int, *MyStruct] = slices.All(testData)
+	table, err = Tabulate(seq2, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	rows := make(chan *MyStruct, len(testData))
+	for _, row := range testData {
+		rows <- row
+	}
+	close(rows)
+	var recv <-chan *MyStruct = rows
+	table, err = Tabulate(recv, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, expecting, table)
+
+	// Nil sources have no rows, rather than blocking
+	empty, err := Tabulate([]*MyStruct{}, &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	table, err = Tabulate((<-chan *MyStruct)(nil), &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, empty, table)
+	table, err = Tabulate(iter.Seq[*MyStruct](nil), &Layout{Format: SimpleFormat})
+	require.Nil(t, err)
+	assert.Equal(t, empty, table)
+}
+
+type Address struct {
+	City    string
+	Country string `tabulate:"-"`
+}
+
+type Audit struct {
+	Version int
+}
+
+type MyNestedStruct struct {
+	Audit
+	Name     string `tabulate:"Who"`
+	Home     *Address
+	Work     Address
+	Location *Address `tabulate:",flatten"`
+}
+
+func TestTabulateNestedStructs(t *testing.T) {
+	records := []*MyNestedStruct{
+		&MyNestedStruct{Audit{1}, "Roy", &Address{"Paris", "France"},
+			Address{"Lyon", "France"}, &Address{"Nice", "France"}},
+		&MyNestedStruct{Audit{2}, "Fred", nil,
+			Address{"Rome", "Italy"}, nil},
+	}
+
+	layout := &Layout{Format: SimpleFormat, FlattenDepth: 1, MissingValue: "-"}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"Version" + "  Who" + " Home.City" + " Work.City" + " Location.City\n" +
+		"-------" + " ----" + " ---------" + " ---------" + " -------------\n" +
+		"      1" + "  Roy" + "     Paris" + "      Lyon" + "          Nice\n" +
+		"      2" + " Fred" + "         -" + "      Rome" + "             -\n")
+	assert.Equal(t, expecting, table)
+
+	_, err = Tabulate(records, &Layout{Format: SimpleFormat})
+	assert.NotNil(t, err, "Nested structs are not Stringers")
+}
+
+type Shadowed struct {
+	ID   int
+	Kind string
+	Note string
+}
+
+type Tagged struct {
+	Kind string `tabulate:"Kind"`
+	Note string
+}
+
+func TestTabulatePromotedFields(t *testing.T) {
+	type row struct {
+		Shadowed
+		Tagged
+		ID int
+	}
+	records := []row{{Shadowed{1, "a", "b"}, Tagged{"c", "d"}, 2}}
+
+	// ID is shadowed by the outer field, Kind goes to the tagged field, and
+	// Note is ambiguous, like it would be for encoding/json.
+	table, err := Tabulate(records, &Layout{Format: PlainFormat})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"Kind ID\n" +
+		"   c  2\n"), table)
+}
+
+type MyNumberStruct struct {
+	Big     uint64
+	Small   int64
+	Price   float64
+	Share   float64
+	Size    float64
+	Traffic int
+	Change  float32
+}
+
+func TestTabulateNumberFormats(t *testing.T) {
+	records := []*MyNumberStruct{
+		&MyNumberStruct{18446744073709551615, -9223372036854775808,
+			1234567.891, 0.255, 3650722201, 1200, 1.5},
+		&MyNumberStruct{0, 42, -0.5, 1, 1023, 999960, 0},
+	}
+
+	layout := &Layout{
+		Format: SimpleFormat,
+		ColumnFormats: map[string]*ColumnFormat{
+			"Price": &ColumnFormat{Number: NumberFormat{
+				Notation: FixedNotation, Precision: 2, Thousands: ",",
+			}},
+			"Share": &ColumnFormat{Number: NumberFormat{
+				Notation: PercentNotation, Precision: 1,
+			}},
+			"Size": &ColumnFormat{Number: NumberFormat{
+				Notation: IECNotation, Precision: 1, Unit: "B",
+			}},
+			"Traffic": &ColumnFormat{Number: NumberFormat{
+				Notation: SINotation, Precision: 1,
+			}},
+			"Change": &ColumnFormat{Number: NumberFormat{
+				Notation: ScientificNotation, Precision: 2,
+				Sign: SignExceptZero,
+			}},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"                 Big" + "                Small" + "        Price" +
+		"  Share" + "       Size" + " Traffic" + "    Change\n" +
+		"--------------------" + " --------------------" + " ------------" +
+		" ------" + " ----------" + " -------" + " ---------\n" +
+		"18446744073709551615" + " -9223372036854775808" + " 1,234,567.89" +
+		"  25.5%" + "    3.4 GiB" + "    1.2k" + " +1.50e+00\n" +
+		"                   0" + "                   42" + "        -0.50" +
+		" 100.0%" + " 1023.0 B  " + "    1.0M" + "  0.00e+00\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateAnchors(t *testing.T) {
+	records := [][]interface{}{
+		[]interface{}{math.NaN(), "3.25", "1:05"},
+		[]interface{}{math.Inf(-1), "1e+21", "12:30:00"},
+		[]interface{}{12.5, "12 GiB", "n/a"},
+		[]interface{}{-1.25, "ñ", "ö:ü"},
+	}
+
+	layout := &Layout{
+		Format:  SimpleFormat,
+		Headers: []string{"float", "text", "time"},
+		ColumnFormats: map[string]*ColumnFormat{
+			"text": &ColumnFormat{Anchor: "."},
+			"time": &ColumnFormat{Anchor: ":"},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"  float" + "   text" + "      time\n" + // 7 + 7 + 10
+		"-------" + " ------" + " ---------\n" +
+		" NaN   " + "  3.25 " + "   1:05   \n" +
+		"-Inf   " + "  1e+21" + "  12:30:00\n" +
+		"  12.5 " + " 12 GiB" + " n/a      \n" +
+		"  -1.25" + "  ñ    " + "   ö:ü    \n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateParseNumbers(t *testing.T) {
+	records := [][]string{
+		[]string{"Montreal", "01234", "1.5", "12"},
+		[]string{"Paris", "75001", "-0.25", "1234"},
+		[]string{"Rome", "00118", "", "7"},
+	}
+
+	layout := &Layout{
+		Format:       SimpleFormat,
+		Headers:      []string{"city", "zip", "ratio", "count"},
+		ParseNumbers: true,
+		ColumnFormats: map[string]*ColumnFormat{
+			"zip": &ColumnFormat{KeepText: true},
+			"count": &ColumnFormat{
+				Number: NumberFormat{Thousands: ","},
+			},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"    city" + "   zip" + " ratio" + " count\n" + // 8 + 6 + 6 + 6
+		"--------" + " -----" + " -----" + " -----\n" +
+		"Montreal" + " 01234" + "  1.5 " + "    12\n" +
+		"   Paris" + " 75001" + " -0.25" + " 1,234\n" +
+		"    Rome" + " 00118" + "      " + "     7\n")
+	assert.Equal(t, expecting, table)
+}
+
+type MyJobStruct struct {
+	Name     string
+	Started  time.Time
+	Finished *time.Time
+	Took     time.Duration
+}
+
+func TestTabulateTimes(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-3 * time.Minute)
+	finished := now.Add(-90 * time.Second)
+	records := []*MyJobStruct{
+		&MyJobStruct{"build", started, &finished, 90*time.Second + 123*time.Millisecond},
+		&MyJobStruct{"deploy", now.Add(2 * time.Hour), nil, 26*time.Hour + 2*time.Minute},
+	}
+
+	paris := time.FixedZone("CET", 60*60)
+	layout := &Layout{
+		Format:       SimpleFormat,
+		MissingValue: "-",
+		ColumnFormats: map[string]*ColumnFormat{
+			"Started": &ColumnFormat{Time: TimeFormat{
+				Layout: "2006-01-02 15:04 MST", Location: paris,
+			}},
+			"Finished": &ColumnFormat{Time: TimeFormat{
+				Relative: true, Now: now,
+			}},
+			"Took": &ColumnFormat{Time: TimeFormat{
+				Round: time.Second, Humanize: true,
+			}},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"  Name" + "              Started" + " Finished" + "   Took\n" + // 6 + 21 + 9 + 7
+		"------" + " --------------------" + " --------" + " ------\n" +
+		" build" + " 2024-03-01 12:57 CET" + "   1m ago" + " 1m 30s\n" +
+		"deploy" + " 2024-03-01 15:00 CET" + "        -" + "  1d 2h\n")
+	assert.Equal(t, expecting, table)
+
+	records[0].Started = time.Now()
+	table, err = Tabulate(records[:1], &Layout{Format: PlainFormat, HideHeaders: true})
+	require.Nil(t, err)
+	assert.NotContains(t, table, "m=+")
+
+	// Unexported times, and Stringers, cannot be read, so are missing
+	type hiddenJob struct {
+		Name    string
+		started time.Time
+		ended   *time.Time
+		owner   *FullName
+		took    time.Duration
+	}
+	hidden := []hiddenJob{
+		{"build", started, &finished, &FullName{"Ada", "Lovelace"}, time.Second},
+	}
+	table, err = Tabulate(hidden, &Layout{Format: PlainFormat, MissingValue: "?"})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" Name started ended owner took\n" +
+		"build       ?     ?     ?   1s\n"), table)
+}
+
+type Status int
+
+// Cell implements the Cell interface
+func (s Status) Cell() (string, Alignment) {
+	if s == 0 {
+		return "ok", AlignLeft
+	}
+	return "failing", AlignCenter
+}
+
+type Version struct {
+	major, minor int
+}
+
+// MarshalText implements the encoding.TextMarshaler interface
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("v%d.%d", v.major, v.minor)), nil
+}
+
+type MyCellStruct struct {
+	Status  Status
+	Version Version
+	Err     error
+	Code    int
+}
+
+func TestTabulateCellMethods(t *testing.T) {
+	records := []*MyCellStruct{
+		&MyCellStruct{0, Version{1, 2}, nil, 255},
+		&MyCellStruct{2, Version{10, 0}, errors.New("timeout"), 16},
+	}
+
+	layout := &Layout{
+		Format:       SimpleFormat,
+		MissingValue: "-",
+		ColumnFormats: map[string]*ColumnFormat{
+			"Code": &ColumnFormat{Verb: "%#x"},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		" Status" + " Version" + "     Err" + " Code\n" + // 7 + 8 + 8 + 5
+		"-------" + " -------" + " -------" + " ----\n" +
+		"ok     " + "    v1.2" + "       -" + " 0xff\n" +
+		"failing" + "   v10.0" + " timeout" + " 0x10\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateFormatters(t *testing.T) {
+	layout := &Layout{
+		Format: SimpleFormat,
+		ColumnFormats: map[string]*ColumnFormat{
+			"amount": &ColumnFormat{
+				Formatter: TypedFormatter(func(amount int) string {
+					return fmt.Sprintf("$%d.00", amount)
+				}),
+			},
+		},
+		IndexFormats: map[int]*ColumnFormat{
+			0: &ColumnFormat{
+				Formatter: func(value interface{}) string {
+					return strings.ToUpper(value.(string))
+				},
+			},
+		},
+	}
+	table, err := Tabulate(testData, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"  name" + " amount\n" + // 6 + 7
+		"------" + " ------\n" +
+		" APPLE" + " $15.00\n" +
+		"ORANGE" + "  $1.00\n")
+	assert.Equal(t, expecting, table)
+}
+
+type MyOrderStruct struct {
+	Item     string
+	Price    float64
+	Quantity int
+}
+
+func TestTabulateComputedColumns(t *testing.T) {
+	records := []*MyOrderStruct{
+		&MyOrderStruct{"Apple", 0.5, 4},
+		&MyOrderStruct{"Orange", 1.25, 3},
+	}
+
+	layout := &Layout{
+		Format: SimpleFormat,
+		Computed: []ComputedColumn{
+			ComputedColumn{"Total", -1, func(row interface{}) interface{} {
+				order := row.(*MyOrderStruct)
+				return order.Price * float64(order.Quantity)
+			}},
+			ComputedColumn{"#", 0, func(row interface{}) interface{} {
+				return len(row.(*MyOrderStruct).Item)
+			}},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"#" + "   Item" + " Price" + " Quantity" + " Total\n" + // 1 + 7 + 6 + 9 + 6
+		"-" + " ------" + " -----" + " --------" + " -----\n" +
+		"5" + "  Apple" + "  0.5 " + "        4" + "  2   \n" +
+		"6" + " Orange" + "  1.25" + "        3" + "  3.75\n")
+	assert.Equal(t, expecting, table)
+}
+
+func TestTabulateSelectColumns(t *testing.T) {
+	records := []*MyNestedStruct{
+		&MyNestedStruct{Audit{1}, "Roy", &Address{"Paris", "France"},
+			Address{"Lyon", "France"}, nil},
+	}
+
+	// Work is a nested struct that cannot be shown as is, but is left out.
+	layout := &Layout{
+		Format: SimpleFormat,
+		Columns: []Column{
+			Column{Name: "Who", Header: "Name"},
+			Column{Name: "Version"},
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+
+	expecting := ("" +
+		"Name" + " Version\n" +
+		"----" + " -------\n" +
+		" Roy" + "       1\n")
+	assert.Equal(t, expecting, table)
+
+	layout.Columns = append(layout.Columns, Column{Name: "Nope"})
+	_, err = Tabulate(records, layout)
+	assert.EqualError(t, err, `No column named "Nope".`)
+}
+
+type MyFileStruct struct {
+	Name string
+	Size *int
+	Kind string
+}
+
+func TestTabulateSortBy(t *testing.T) {
+	small, big := 10, 2000
+	records := []MyFileStruct{
+		MyFileStruct{"file10", &small, "log"},
+		MyFileStruct{"file9", &big, "log"},
+		MyFileStruct{"file2", nil, "data"},
+		MyFileStruct{"file1", &small, "data"},
+	}
+
+	layout := &Layout{
+		Format:       PlainFormat,
+		HideHeaders:  true,
+		MissingValue: "-",
+		SortBy:       []SortKey{SortKey{Name: "Name"}},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" file1   10 data\n" +
+		" file2    - data\n" +
+		" file9 2000  log\n" +
+		"file10   10  log\n"), table)
+
+	layout.SortBy = []SortKey{
+		SortKey{Name: "Size", Descending: true, NilsFirst: true},
+		SortKey{Name: "Kind"},
+	}
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" file2    - data\n" +
+		" file9 2000  log\n" +
+		" file1   10 data\n" +
+		"file10   10  log\n"), table)
+}
+
+func TestTabulateFilter(t *testing.T) {
+	records := []*MyStruct{
+		&MyStruct{"Apple", 15},
+		&MyStruct{"Watermelon", 1000},
+		&MyStruct{"Orange", 1},
+	}
+
+	layout := &Layout{
+		Format: SimpleFormat,
+		Filter: func(row interface{}) bool {
+			return row.(*MyStruct).amount < 100
+		},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  name" + " amount\n" + // 6 + 7
+		"------" + " ------\n" +
+		" Apple" + "     15\n" +
+		"Orange" + "      1\n"), table)
+}
+
+func TestTabulateWhere(t *testing.T) {
+	records := [][]string{
+		[]string{"file10", "10"},
+		[]string{"file9", "2000"},
+		[]string{"file2", "7"},
+	}
+
+	over, err := ParseCondition("size >= 10")
+	require.Nil(t, err)
+	assert.Equal(t, Condition{"size", ">=", "10"}, over)
+	after, err := ParseCondition("name>file2")
+	require.Nil(t, err)
+	_, err = ParseCondition("name")
+	assert.NotNil(t, err)
+
+	layout := &Layout{
+		Format:      PlainFormat,
+		Headers:     []string{"name", "size"},
+		HideHeaders: true,
+		Where:       []Condition{after},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"file10   10\n" +
+		" file9 2000\n"), table)
+
+	// size holds strings, which compare naturally, so "7" is below "10"
+	layout.Where = []Condition{over, Condition{"name", "!~", "9"}}
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, "file10 10\n", table)
+}
+
+func TestTabulateFooters(t *testing.T) {
+	type fruit struct {
+		Name   string
+		Amount float64
+	}
+	fruits := []fruit{{"Apple", 15}, {"Orange", 1.5}}
+
+	layout := &Layout{
+		Format: SimpleFormat,
+		Footers: []Footer{
+			{
+				Text:       map[string]string{"Name": "Total"},
+				Aggregates: map[string]Aggregate{"Amount": Sum},
+			},
+			{Aggregates: map[string]Aggregate{"Amount": Mean}},
+		},
+	}
+	table, err := Tabulate(fruits, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  Name Amount\n" +
+		"------ ------\n" +
+		" Apple  15   \n" +
+		"Orange   1.5 \n" +
+		"------ ------\n" +
+		" Total  16.5 \n" +
+		"         8.25\n"), table)
+
+	layout.Format = FancyGridFormat
+	table, err = Tabulate(fruits, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"╒════════╤════════╕\n" +
+		"│   Name │ Amount │\n" +
+		"╞════════╪════════╡\n" +
+		"│  Apple │  15    │\n" +
+		"├────────┼────────┤\n" +
+		"│ Orange │   1.5  │\n" +
+		"╞════════╪════════╡\n" +
+		"│  Total │  16.5  │\n" +
+		"│        │   8.25 │\n" +
+		"╘════════╧════════╛\n"), table)
+}
+
+func TestFooterAggregates(t *testing.T) {
+	assert.Equal(t, int64(3), Sum([]interface{}{1, uint8(2), "x"}))
+	assert.Equal(t, uint64(math.MaxUint64), Sum([]interface{}{
+		uint64(math.MaxUint64 - 1), uint(1),
+	}))
+	assert.Equal(t, "18446744073709551616", fmt.Sprint(Sum([]interface{}{
+		uint64(math.MaxUint64), 1,
+	})))
+	assert.Equal(t, "-9223372036854775809", fmt.Sprint(Sum([]interface{}{
+		int64(math.MinInt64), -1,
+	})))
+	assert.Equal(t, 3.5, Sum([]interface{}{1, 2.5}))
+	assert.Equal(t, float32(0.3), Sum([]interface{}{float32(0.1), float32(0.2)}))
+	assert.Equal(t, float32(0.15), Mean([]interface{}{float32(0.1), float32(0.2)}))
+	assert.Nil(t, Mean([]interface{}{"x"}))
+
+	type reading struct {
+		Value float32
+	}
+	layout := &Layout{
+		Format:  PlainFormat,
+		Footers: []Footer{{Aggregates: map[string]Aggregate{"Value": Sum}}},
+	}
+	table, err := Tabulate([]reading{{0.1}, {0.2}}, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"Value\n" +
+		"  0.1\n" +
+		"  0.2\n" +
+		"  0.3\n"), table)
+}
+
+func TestTabulateIndex(t *testing.T) {
+	records := [][]string{
+		[]string{"Orange", "1"},
+		[]string{"Apple", "15"},
+		[]string{"Pear", "3"},
+	}
+	layout := &Layout{
+		Format:  SimpleFormat,
+		Headers: []string{"name", "amount"},
+		Index:   &RowIndex{Header: "#", Start: 1},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"#   name amount\n" +
+		"- ------ ------\n" +
+		"1 Orange      1\n" +
+		"2  Apple     15\n" +
+		"3   Pear      3\n"), table)
+
+	// Rows keep their index when sorted or filtered...
+	layout.SortBy = []SortKey{{Name: "name"}}
+	layout.Where = []Condition{{"name", "!=", "Pear"}}
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"#   name amount\n" +
+		"- ------ ------\n" +
+		"2  Apple     15\n" +
+		"1 Orange      1\n"), table)
+
+	// ...unless renumbered.
+	layout.Index.Renumber = true
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"#   name amount\n" +
+		"- ------ ------\n" +
+		"1  Apple     15\n" +
+		"2 Orange      1\n"), table)
+
+	layout.Index = &RowIndex{Labels: []string{"a", "b", "c"}}
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"    name amount\n" +
+		"- ------ ------\n" +
+		"b  Apple     15\n" +
+		"a Orange      1\n"), table)
+
+	layout.Index.Labels = []string{"a"}
+	_, err = Tabulate(records, layout)
+	assert.NotNil(t, err)
+
+	// Filtering out every row leaves the index empty too
+	table, err = Tabulate(records, &Layout{
+		Format:  SimpleFormat,
+		Headers: []string{"name", "amount"},
+		Filter:  func(interface{}) bool { return false },
+		Index:   &RowIndex{Header: "#"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"# name amount\n" +
+		"- ---- ------\n"), table)
+}
+
+func TestPaginate(t *testing.T) {
+	records := [][]string{
+		[]string{"Apple", "15"},
+		[]string{"Orange", "1"},
+		[]string{"Pear", "3"},
+	}
+	layout := &Layout{
+		Format:  GridFormat,
+		Headers: []string{"name", "amount"},
+	}
+
+	pages, err := Paginate(records, layout, Paging{
+		Rows: 2,
+		Caption: func(page int, pages int) string {
+			return fmt.Sprintf("page %d/%d", page, pages)
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		("" +
+			"+--------+--------+\n" +
+			"|   name | amount |\n" +
+			"+========+========+\n" +
+			"|  Apple |     15 |\n" +
+			"+--------+--------+\n" +
+			"| Orange |      1 |\n" +
+			"+--------+--------+\n" +
+			"page 1/2\n"),
+		("" +
+			"+--------+--------+\n" +
+			"|   name | amount |\n" +
+			"+========+========+\n" +
+			"|   Pear |      3 |\n" +
+			"+--------+--------+\n" +
+			"page 2/2\n"),
+	}, pages)
+
+	// Two rows and the line between them take up three lines.
+	pages, err = Paginate(records, layout, Paging{Lines: 2})
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(pages))
+	pages, err = Paginate(records, layout, Paging{Lines: 3})
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(pages))
+
+	var written strings.Builder
+	layout.Format = PlainFormat
+	err = WritePages(&written, records, layout, Paging{Rows: 2})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  name amount\n" +
+		" Apple     15\n" +
+		"Orange      1\n" +
+		"  name amount\n" +
+		"  Pear      3\n"), written.String())
+}
+
+func TestTabulateSplit(t *testing.T) {
+	type planet struct {
+		Name   string
+		Mass   float64
+		Radius int
+		Moons  int
+	}
+	planets := []planet{{"Earth", 5.97, 6371, 1}, {"Mars", 0.642, 3390, 2}}
+
+	table, err := TabulateSplit(
+		planets, &Layout{Format: GridFormat},
+		Splitting{Width: 30, Keys: []string{"Name"}},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"+-------+-------+--------+\n" +
+		"|  Name |  Mass | Radius |\n" +
+		"+=======+=======+========+\n" +
+		"| Earth | 5.97  |   6371 |\n" +
+		"+-------+-------+--------+\n" +
+		"|  Mars | 0.642 |   3390 |\n" +
+		"+-------+-------+--------+\n" +
+		"+-------+-------+         \n" +
+		"|  Name | Moons |         \n" +
+		"+=======+=======+         \n" +
+		"| Earth |     1 |         \n" +
+		"+-------+-------+         \n" +
+		"|  Mars |     2 |         \n" +
+		"+-------+-------+         \n"), table)
+
+	table, err = TabulateSplit(
+		planets, &Layout{Format: SimpleFormat},
+		Splitting{Width: 20, Keys: []string{"Name"}, Padding: " "},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" Name  Mass Radius\n" +
+		"----- ----- ------\n" +
+		"Earth 5.97    6371\n" +
+		" Mars 0.642   3390\n" +
+		"                  \n" +
+		" Name Moons       \n" +
+		"----- -----       \n" +
+		"Earth     1       \n" +
+		" Mars     2       \n"), table)
+
+	_, err = TabulateSplit(
+		planets, &Layout{}, Splitting{Width: 10, Keys: []string{"Age"}},
+	)
+	assert.NotNil(t, err)
+
+	// Keys are checked even if the table fits
+	_, err = TabulateSplit(
+		planets, &Layout{}, Splitting{Width: 100, Keys: []string{"Age"}},
+	)
+	assert.NotNil(t, err)
+
+	// A table of nothing but keys is left whole
+	keysOnly := &Layout{Columns: []Column{{Name: "Name"}, {Name: "Mass"}}}
+	whole, err := Tabulate(planets, keysOnly)
+	require.Nil(t, err)
+	table, err = TabulateSplit(
+		planets, keysOnly,
+		Splitting{Width: 3, Keys: []string{"Name", "Mass"}},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, whole, table)
+
+	// Tables that fit are left as they are
+	whole, err = Tabulate(planets, &Layout{Format: PlainFormat})
+	require.Nil(t, err)
+	for _, width := range []int{0, 25} {
+		table, err = TabulateSplit(
+			planets, &Layout{Format: PlainFormat},
+			Splitting{Width: width, Keys: []string{"Moons"}},
+		)
+		require.Nil(t, err)
+		assert.Equal(t, whole, table)
+	}
+}
+
+func TestTabulateViews(t *testing.T) {
+	type fruit struct {
+		Name   string
+		Amount float64
+	}
+	fruits := []fruit{{"Apple", 15}, {"Orange", 1.5}}
+
+	layout := &Layout{View: ExpandedView}
+	table, err := Tabulate(fruits, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"-[ RECORD 1 ]--\n" +
+		"Name   | Apple\n" +
+		"Amount | 15\n" +
+		"-[ RECORD 2 ]--\n" +
+		"Name   | Orange\n" +
+		"Amount | 1.5\n"), table)
+
+	layout.View = TransposedView
+	layout.Format = GridFormat
+	table, err = Tabulate(fruits, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"+--------+-------+--------+\n" +
+		"|   Name | Apple | Orange |\n" +
+		"+--------+-------+--------+\n" +
+		"| Amount |    15 |    1.5 |\n" +
+		"+--------+-------+--------+\n"), table)
+
+	layout = &Layout{ExpandWidth: 12}
+	table, err = Tabulate(fruits, layout)
+	require.Nil(t, err)
+	// The table is 13 characters wide
+	assert.Equal(t, ("" +
+		"-[ RECORD 1 ]--\n" +
+		"Name   | Apple\n" +
+		"Amount | 15\n" +
+		"-[ RECORD 2 ]--\n" +
+		"Name   | Orange\n" +
+		"Amount | 1.5\n"), table)
+	layout.ExpandWidth = 13
+	table, err = Tabulate(fruits, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  Name Amount\n" +
+		"------ ------\n" +
+		" Apple   15  \n" +
+		"Orange    1.5\n"), table)
+}
+
+func TestTabulateGroupBy(t *testing.T) {
+	type item struct {
+		Kind   string
+		Name   string
+		Amount int
+	}
+	items := []item{
+		{"fruit", "Apple", 15},
+		{"veg", "Leek", 2},
+		{"fruit", "Orange", 1},
+	}
+
+	layout := &Layout{Format: SimpleFormat, GroupBy: "Kind"}
+	table, err := Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" Kind   Name Amount\n" +
+		"----- ------ ------\n" +
+		"fruit  Apple     15\n" +
+		"      Orange      1\n" +
+		"----- ------ ------\n" +
+		"  veg   Leek      2\n"), table)
+
+	layout.Format = PlainFormat
+	table, err = Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" Kind   Name Amount\n" +
+		"fruit  Apple     15\n" +
+		"      Orange      1\n" +
+		"\n" +
+		"  veg   Leek      2\n"), table)
+
+	layout.Format = FancyGridFormat
+	layout.GroupHeadings = true
+	table, err = Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"╒════════╤════════╕\n" +
+		"│   Name │ Amount │\n" +
+		"╞════════╧════════╡\n" +
+		"│ fruit           │\n" +
+		"├────────┬────────┤\n" +
+		"│  Apple │     15 │\n" +
+		"├────────┼────────┤\n" +
+		"│ Orange │      1 │\n" +
+		"╞════════╧════════╡\n" +
+		"│ veg             │\n" +
+		"├────────┬────────┤\n" +
+		"│   Leek │      2 │\n" +
+		"╘════════╧════════╛\n"), table)
+
+	layout.Format = GridFormat
+	layout.Columns = []Column{{Name: "Amount"}}
+	table, err = Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"+--------+\n" +
+		"| Amount |\n" +
+		"+========+\n" +
+		"| fruit  |\n" +
+		"+--------+\n" +
+		"|     15 |\n" +
+		"+--------+\n" +
+		"|      1 |\n" +
+		"+========+\n" +
+		"| veg    |\n" +
+		"+--------+\n" +
+		"|      2 |\n" +
+		"+--------+\n"), table)
+
+	pages, err := Paginate(items, layout, Paging{Lines: 5})
+	require.Nil(t, err)
+	// Group headings take up lines too
+	assert.Equal(t, 2, len(pages))
+	top := ("" +
+		"+--------+\n" +
+		"| Amount |\n" +
+		"+========+\n" +
+		"| veg    |\n")
+	assert.True(t, strings.HasPrefix(pages[1], top))
+
+	// A page starting in the middle of a group repeats its heading
+	pages, err = Paginate(items, layout, Paging{Rows: 1})
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(pages))
+	assert.Equal(t, ("" +
+		"+--------+\n" +
+		"| Amount |\n" +
+		"+========+\n" +
+		"| fruit  |\n" +
+		"+--------+\n" +
+		"|      1 |\n" +
+		"+--------+\n"), pages[1])
+
+	// and its key, without headings
+	layout = &Layout{Format: PlainFormat, GroupBy: "Kind"}
+	pages, err = Paginate(items, layout, Paging{Rows: 1})
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(pages))
+	assert.Equal(t, ("" +
+		" Kind   Name Amount\n" +
+		"fruit Orange      1\n"), pages[1])
+
+	// The other views show the key in every record
+	layout.View = TransposedView
+	table, err = Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  Kind fruit  fruit  veg\n" +
+		"  Name Apple Orange Leek\n" +
+		"Amount    15      1    2\n"), table)
+
+	layout.View = ExpandedView
+	layout.GroupHeadings = true
+	table, err = Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Contains(t, table, "Kind   | veg\n")
+}
+
+func TestTabulateGroupByExactKeys(t *testing.T) {
+	type file struct {
+		Name string
+		Size int
+	}
+	files := []file{{"file01", 1}, {"file1", 2}, {"file01", 3}}
+
+	// Keys equal when sorted are still different groups
+	table, err := Tabulate(files, &Layout{Format: PlainFormat, GroupBy: "Name"})
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  Name Size\n" +
+		"file01    1\n" +
+		"          3\n" +
+		"\n" +
+		" file1    2\n"), table)
+
+	// and grouping nothing keeps the index empty
+	table, err = Tabulate(files, &Layout{
+		Format:  PlainFormat,
+		GroupBy: "Name",
+		Filter:  func(interface{}) bool { return false },
+		Index:   &RowIndex{Header: "#"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "# Name Size\n", table)
+}
+
+// customFormat has no line of its own between groups.
+type customFormat struct{ TableFormatterInterface }
+
+func TestTabulateGroupByCustomFormat(t *testing.T) {
+	type item struct {
+		Kind string
+		Name string
+	}
+	items := []item{{"fruit", "Apple"}, {"veg", "Leek"}}
+	layout := &Layout{
+		Format:  customFormat{SimpleFormat},
+		GroupBy: "Kind",
+	}
+	table, err := Tabulate(items, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" Kind  Name\n" +
+		"----- -----\n" +
+		"fruit Apple\n" +
+		"----- -----\n" +
+		"  veg  Leek\n"), table)
+}
+
+func TestTabulateSpan(t *testing.T) {
+	records := [][]interface{}{
+		[]interface{}{"Apple", 15, 1.5},
+		[]interface{}{"Kiwi", Span{"N/A for this section", 2, AlignCenter}, nil},
+		[]interface{}{Span{Value: "Pear", Columns: 3}},
+	}
+	layout := &Layout{
+		Format:  FancyGridFormat,
+		Headers: []string{"name", "amount", "price"},
+	}
+	table, err := Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"╒═══════╤════════╤═════════════╕\n" +
+		"│  name │ amount │       price │\n" +
+		"╞═══════╪════════╪═════════════╡\n" +
+		"│ Apple │     15 │         1.5 │\n" +
+		"├───────┼────────┴─────────────┤\n" +
+		"│  Kiwi │ N/A for this section │\n" +
+		"├───────┴──────────────────────┤\n" +
+		"│                         Pear │\n" +
+		"╘══════════════════════════════╛\n"), table)
+
+	layout.Format = GridFormat
+	layout.HideHeaders = true
+	table, err = Tabulate(records[1:], layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"+------+----------------------+\n" +
+		"| Kiwi | N/A for this section |\n" +
+		"+------+----------------------+\n" +
+		"|                        Pear |\n" +
+		"+-----------------------------+\n"), table)
+
+	layout.Format = PipeFormat
+	layout.HideHeaders = false
+	table, err = Tabulate(records, layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" name | amount |       price\n" +
+		"----- | ------ | -----------\n" +
+		"Apple |     15 |         1.5\n" +
+		" Kiwi | N/A for this section\n" +
+		"                        Pear\n"), table)
+
+	// Spans are cut short at the edge of a block, and covered cells
+	// are left blank
+	layout.Format = SimpleFormat
+	table, err = TabulateSplit(
+		records, layout, Splitting{Width: 25, Keys: []string{"name"}},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		" name               amount\n" +
+		"----- --------------------\n" +
+		"Apple                   15\n" +
+		" Kiwi N/A for this section\n" +
+		"                      Pear\n" +
+		" name price               \n" +
+		"----- -----               \n" +
+		"Apple   1.5               \n" +
+		" Kiwi                     \n" +
+		" Pear                     \n"), table)
+
+	// The other views leave the covered cells out
+	layout.View = ExpandedView
+	table, err = Tabulate(records[1:], layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"-[ RECORD 1 ]----------------\n" +
+		"name   | Kiwi\n" +
+		"amount | N/A for this section\n" +
+		"-[ RECORD 2 ]----------------\n" +
+		"name   | Pear\n"), table)
+
+	layout.View = TransposedView
+	table, err = Tabulate(records[1:], layout)
+	require.Nil(t, err)
+	assert.Equal(t, ("" +
+		"  name                 Kiwi Pear\n" +
+		"amount N/A for this section     \n" +
+		" price                          \n"), table)
+}
@@ -0,0 +1,139 @@
+package tabulate
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// TimeFormat determines how the time.Time and time.Duration cells of a
+// column are written out. The zero value writes times out like their
+// String method, minus the monotonic clock reading, and durations as is.
+type TimeFormat struct {
+	// Layout is the layout times are written out with, as understood by
+	// time.Time's Format method, such as time.RFC3339 or "15:04".
+	Layout string
+	// Location converts times to this time zone before writing them out.
+	Location *time.Location
+	// Relative writes times out relative to Now, like "3m ago" or
+	// "in 2h", instead of with Layout.
+	Relative bool
+	// Now is what relative times are relative to. It defaults to when the
+	// table is built.
+	Now time.Time
+
+	// Round rounds durations, and relative times, to a multiple of it.
+	Round time.Duration
+	// Humanize writes durations out with their two largest units, like
+	// "1h 2m" or "3d 4h", instead of like "26h2m3.456789s".
+	Humanize bool
+}
+
+// timeCaster returns a caster for time.Time and time.Duration cells, or nil
+// if cellType is neither. Times in unexported struct fields cannot be read,
+// and are shown as missing.
+func timeCaster(cellType reflect.Type, format *TimeFormat,
+	missing string) func(reflect.Value) string {
+
+	switch cellType {
+	case timeType:
+		now := format.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		return func(value reflect.Value) string {
+			if !value.CanInterface() {
+				return missing
+			}
+			return format.formatTime(value.Interface().(time.Time), now)
+		}
+	case durationType:
+		return func(value reflect.Value) string {
+			return format.formatDuration(time.Duration(value.Int()))
+		}
+	}
+	return nil
+}
+
+func (f *TimeFormat) formatTime(when time.Time, now time.Time) string {
+	if f.Relative {
+		since := f.round(now.Sub(when))
+		switch {
+		case since > -time.Second && since < time.Second:
+			return "now"
+		case since > 0:
+			return humanize(since, 1) + " ago"
+		default:
+			return "in " + humanize(-since, 1)
+		}
+	}
+
+	if f.Location != nil {
+		when = when.In(f.Location)
+	}
+	if f.Layout == "" {
+		// Round(0) strips the monotonic clock reading
+		return when.Round(0).String()
+	}
+	return when.Format(f.Layout)
+}
+
+func (f *TimeFormat) formatDuration(duration time.Duration) string {
+	duration = f.round(duration)
+	if f.Humanize {
+		if duration < 0 {
+			return "-" + humanize(-duration, 2)
+		}
+		return humanize(duration, 2)
+	}
+	return duration.String()
+}
+
+func (f *TimeFormat) round(duration time.Duration) time.Duration {
+	if f.Round > 0 {
+		return duration.Round(f.Round)
+	}
+	return duration
+}
+
+var humanUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"µs", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// humanize writes out a positive duration with (at most) its largest parts
+// units, skipping empty units: 26h2m3s is "1d 2h" with two parts.
+func humanize(duration time.Duration, parts int) string {
+	var output []string
+
+	for _, unit := range humanUnits {
+		if len(output) == parts {
+			break
+		}
+		count := duration / unit.size
+		if count > 0 {
+			output = append(output, strconv.FormatInt(int64(count), 10)+unit.suffix)
+			duration -= count * unit.size
+		} else if len(output) > 0 {
+			// Parts must be adjacent units, or 1d 3s would read oddly.
+			break
+		}
+	}
+
+	if len(output) == 0 {
+		return "0s"
+	}
+	return strings.Join(output, " ")
+}